@@ -2,33 +2,72 @@ package main
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/platonso/order-viewer/internal/app"
 	"github.com/platonso/order-viewer/internal/config"
+	"github.com/platonso/order-viewer/internal/logging"
+	"github.com/platonso/order-viewer/internal/tracing"
 	"github.com/platonso/order-viewer/migrations"
 )
 
 func main() {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	if err := run(); err != nil {
+		slog.Error("service stopped with error", logging.Err(err))
+		os.Exit(1)
+	}
+}
+
+// run выполняется до SIGINT/SIGTERM; отложенные вызовы закрывают бд и выгружают спаны до выхода из процесса.
+func run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	cfg, err := config.NewConfig()
 	if err != nil {
-		log.Fatalf("Config error: %v", err)
+		return fmt.Errorf("config error: %w", err)
+	}
+
+	if err := logging.Setup(cfg.LogFormat, cfg.LogLevel); err != nil {
+		return fmt.Errorf("logging config error: %w", err)
+	}
+
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+		ServiceName: cfg.TracingServiceName,
+		Exporter:    cfg.TracingExporter,
+		Endpoint:    cfg.TracingEndpoint,
+		Insecure:    cfg.TracingInsecure,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		return fmt.Errorf("tracing config error: %w", err)
 	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("failed to flush traces", logging.Err(err))
+		}
+	}()
 
 	if err := migrations.Run(ctx, cfg.GetConnStr()); err != nil {
-		log.Fatalf("Migrations failed: %v", err)
+		return fmt.Errorf("migrations failed: %w", err)
 	}
 
 	application, err := app.NewApp(ctx, cfg)
 	if err != nil {
-		log.Fatalf("Failed to init app: %v", err)
+		return fmt.Errorf("failed to init app: %w", err)
 	}
 	defer application.DB.Close()
 
 	if err := application.Run(ctx); err != nil {
-		log.Fatalf("Server run error: %v", err)
+		return fmt.Errorf("server run error: %w", err)
 	}
+	slog.Info("service stopped")
+	return nil
 }
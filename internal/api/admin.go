@@ -0,0 +1,24 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/platonso/order-viewer/internal/kafka"
+)
+
+type AdminHandler struct {
+	consumer *kafka.Consumer // nil, если консьюмер не запустился
+}
+
+func NewAdminHandler(consumer *kafka.Consumer) *AdminHandler {
+	return &AdminHandler{consumer: consumer}
+}
+
+func (h *AdminHandler) ConsumerStatus(w http.ResponseWriter, r *http.Request) {
+	if h.consumer == nil {
+		writeErrorCode(w, r, http.StatusServiceUnavailable, CodeServiceUnavailable, "kafka consumer is not running")
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, h.consumer.Status())
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/platonso/order-viewer/internal/auth"
+)
+
+// apiKeyHeader — заголовок со статическим API-ключом.
+const apiKeyHeader = "X-API-Key"
+
+// authenticate определяет вызывающую сторону по X-API-Key или Bearer-токену.
+func authenticate(authn *auth.Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var (
+				p   *auth.Principal
+				err error
+			)
+			if key := r.Header.Get(apiKeyHeader); key != "" {
+				p, err = authn.AuthenticateAPIKey(key)
+			} else if token, ok := bearerToken(r); ok {
+				p, err = authn.AuthenticateToken(token)
+			} else {
+				p, err = authn.Anonymous()
+			}
+
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="order-viewer"`)
+				writeErrorCode(w, r, http.StatusUnauthorized, CodeUnauthorized, "valid API key or bearer token is required")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), p)))
+		})
+	}
+}
+
+// requireRole пропускает запрос, только если у вызывающей стороны есть роль role.
+func requireRole(role auth.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !auth.FromContext(r.Context()).HasRole(role) {
+				writeErrorCode(w, r, http.StatusForbidden, CodeForbidden, "role "+string(role)+" is required")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
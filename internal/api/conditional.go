@@ -0,0 +1,72 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// orderCacheControl — заказы после приёма не меняются, но содержат персональные данные,
+// поэтому кэшировать их можно только в браузере клиента.
+const orderCacheControl = "private, max-age=3600"
+
+// orderETag возвращает ETag представления заказа. Заказ неизменяем, поэтому
+// тело однозначно определяется идентификатором, форматом, отступами и маскированием.
+// ETag слабый: компрессор отдаёт то же представление в identity, gzip и zstd,
+// а сильный валидатор обязан различать эти байты (RFC 9110, 8.8.3.3).
+func orderETag(orderUID, contentType string, pretty, showPII bool) string {
+	h := sha256.New()
+	h.Write([]byte(orderUID))
+	h.Write([]byte{0})
+	h.Write([]byte(contentType))
+	h.Write([]byte{0, boolByte(pretty), boolByte(showPII)})
+	sum := h.Sum(nil)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// setValidators выставляет заголовки ETag, Last-Modified и Cache-Control.
+func setValidators(w http.ResponseWriter, etag string, lastModified time.Time) {
+	w.Header().Set("ETag", etag)
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	w.Header().Set("Cache-Control", orderCacheControl)
+}
+
+// notModified проверяет If-None-Match, а при его отсутствии — If-Modified-Since (RFC 9110, 13.2.2).
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag)
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+		// Last-Modified передаётся с точностью до секунды
+		return !lastModified.Truncate(time.Second).After(t)
+	}
+
+	return false
+}
+
+// etagMatches выполняет слабое сравнение, как требуется для If-None-Match.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
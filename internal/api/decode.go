@@ -0,0 +1,59 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// decodeJSONBody читает тело запроса с ограничением размера и проверяет,
+// что после JSON-значения нет лишних данных. В строгом режиме неизвестные поля запрещены.
+// При ошибке ответ уже отправлен, и вызывающему остаётся только выйти.
+func (h *Handler) decodeJSONBody(w http.ResponseWriter, r *http.Request, dst any) bool {
+	body := http.MaxBytesReader(w, r.Body, h.opts.MaxBodyBytes)
+
+	if err := h.decodeJSON(body, dst); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeErrorCode(w, r, http.StatusRequestEntityTooLarge, CodeRequestTooLarge,
+				fmt.Sprintf("request body must not exceed %d bytes", maxBytesErr.Limit))
+			return false
+		}
+		writeErrorCode(w, r, http.StatusBadRequest, CodeInvalidRequestBody, "invalid request body: "+err.Error())
+		return false
+	}
+
+	return true
+}
+
+func (h *Handler) decodeJSON(r io.Reader, dst any) error {
+	dec := json.NewDecoder(r)
+	if h.opts.StrictDecoding {
+		dec.DisallowUnknownFields()
+	}
+
+	if err := dec.Decode(dst); err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.New("body is empty")
+		}
+		return err
+	}
+
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return err
+		}
+		return errors.New("body must contain a single JSON value")
+	}
+
+	return nil
+}
+
+// decodeJSONBytes применяет те же правила к уже прочитанному фрагменту, например строке NDJSON.
+func (h *Handler) decodeJSONBytes(data []byte, dst any) error {
+	return h.decodeJSON(bytes.NewReader(data), dst)
+}
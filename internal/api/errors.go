@@ -0,0 +1,105 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/platonso/order-viewer/internal/domain"
+	"github.com/platonso/order-viewer/internal/logging"
+)
+
+// Машиночитаемые коды ошибок API.
+const (
+	CodeInvalidRequestBody   = "invalid_request_body"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeNotAcceptable        = "not_acceptable"
+	CodeValidationFailed     = "validation_failed"
+	CodeOrderNotFound        = "order_not_found"
+	CodeOrderAlreadyExists   = "order_already_exists"
+	CodeServiceUnavailable   = "service_unavailable"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeRequestTooLarge      = "request_too_large"
+	CodeRateLimited          = "rate_limited"
+	CodeQueryTooComplex      = "query_too_complex"
+	CodeInternalError        = "internal_error"
+)
+
+// ErrorResponse — единый формат ошибок API.
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Field     string       `json:"field,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Details   []FieldIssue `json:"details,omitempty"`
+}
+
+// FieldIssue — нарушение правила валидации для конкретного поля.
+type FieldIssue struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+// writeError сопоставляет ошибку сервиса с HTTP-статусом и кодом ответа.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var (
+		validationErrs domain.ValidationErrors
+		fieldErr       *domain.FieldError
+	)
+
+	switch {
+	case errors.As(err, &validationErrs):
+		writeErrorBody(w, r, http.StatusBadRequest, validationErrorBody(validationErrs...))
+	case errors.As(err, &fieldErr):
+		writeErrorBody(w, r, http.StatusBadRequest, validationErrorBody(fieldErr))
+	case errors.Is(err, domain.ErrValidation):
+		writeErrorBody(w, r, http.StatusBadRequest, ErrorBody{Code: CodeValidationFailed, Message: "request validation failed"})
+	case errors.Is(err, domain.ErrOrderNotFound):
+		writeErrorBody(w, r, http.StatusNotFound, ErrorBody{Code: CodeOrderNotFound, Message: "order not found"})
+	case errors.Is(err, domain.ErrOrderAlreadyExists):
+		writeErrorBody(w, r, http.StatusConflict, ErrorBody{Code: CodeOrderAlreadyExists, Message: "order already exists"})
+	default:
+		slog.ErrorContext(r.Context(), "request failed", "method", r.Method, "path", r.URL.Path, logging.Err(err))
+		writeErrorBody(w, r, http.StatusInternalServerError, ErrorBody{Code: CodeInternalError, Message: "internal server error"})
+	}
+}
+
+func validationErrorBody(errs ...*domain.FieldError) ErrorBody {
+	body := ErrorBody{
+		Code:    CodeValidationFailed,
+		Message: "request validation failed",
+		Details: make([]FieldIssue, 0, len(errs)),
+	}
+	for _, e := range errs {
+		body.Details = append(body.Details, FieldIssue{Field: e.Field, Rule: e.Rule, Message: e.Message})
+	}
+	if len(errs) == 1 {
+		body.Field = errs[0].Field
+	}
+	return body
+}
+
+// writeErrorCode отправляет ошибку, не связанную с доменными ошибками сервиса.
+func writeErrorCode(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	writeErrorBody(w, r, status, ErrorBody{Code: code, Message: message})
+}
+
+func writeErrorBody(w http.ResponseWriter, r *http.Request, status int, body ErrorBody) {
+	body.RequestID = logging.RequestID(r.Context())
+
+	data, err := encodeJSON(r, ErrorResponse{Error: body})
+	if err != nil {
+		http.Error(w, body.Message, status)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
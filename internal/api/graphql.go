@@ -0,0 +1,226 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// GraphQLLimits ограничивает стоимость запроса до его выполнения.
+type GraphQLLimits struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// Множители сложности для списков: страница заказов без явного first совпадает с лимитом
+// по умолчанию, для товаров и предупреждений берётся типичный размер заказа.
+const (
+	defaultPageSize = 20
+	defaultListSize = 10
+)
+
+// orderLoadCost — надбавка за загрузку полного заказа: доставка, оплата и товары
+// читаются из отдельных таблиц, а краткое представление списка их не содержит.
+const orderLoadCost = 10
+
+// summaryFields — поля Order, которые отдаются из краткого представления без загрузки заказа.
+var summaryFields = map[string]bool{
+	"order_uid":        true,
+	"track_number":     true,
+	"customer_id":      true,
+	"delivery_service": true,
+	"date_created":     true,
+}
+
+// selectionLevel — чей набор полей считается: от него зависят множители и надбавки.
+type selectionLevel int
+
+const (
+	levelQuery     selectionLevel = iota
+	levelPage                     // OrderPage из orders
+	levelPageOrder                // заказ страницы, загружается, только если запрошены поля вне summaryFields
+	levelNested
+)
+
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// GraphQL — POST /graphql (и GET с параметром query): чтение заказов с выбором полей.
+func (h *Handler) GraphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphQLRequest
+	if r.Method == http.MethodGet {
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+	} else if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"})})
+	if err != nil {
+		writeGraphQLErrors(w, r, gqlerrors.FormatErrors(err))
+		return
+	}
+
+	if res := graphql.ValidateDocument(&h.graphql, doc, nil); !res.IsValid {
+		writeGraphQLErrors(w, r, res.Errors)
+		return
+	}
+
+	if err := h.checkGraphQLLimits(doc, req.OperationName, req.Variables); err != nil {
+		writeGraphQLErrors(w, r, []gqlerrors.FormattedError{{Message: err.Error(), Extensions: err.Extensions()}})
+		return
+	}
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.graphql,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       r.Context(),
+	})
+	writeJSON(w, r, http.StatusOK, result)
+}
+
+// writeGraphQLErrors отвечает 400 на запрос, который не дошёл до выполнения.
+func writeGraphQLErrors(w http.ResponseWriter, r *http.Request, errs []gqlerrors.FormattedError) {
+	writeJSON(w, r, http.StatusBadRequest, graphql.Result{Errors: errs})
+}
+
+// checkGraphQLLimits считает глубину и сложность выбранной операции: каждое поле стоит 1,
+// поля внутри списка умножаются на его ожидаемый размер, а каждая загрузка полного заказа
+// добавляет orderLoadCost.
+func (h *Handler) checkGraphQLLimits(doc *ast.Document, operationName string, variables map[string]interface{}) *gqlError {
+	fragments := make(map[string]*ast.FragmentDefinition)
+	var operation *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch d := def.(type) {
+		case *ast.FragmentDefinition:
+			fragments[d.Name.Value] = d
+		case *ast.OperationDefinition:
+			if operationName == "" || (d.Name != nil && d.Name.Value == operationName) {
+				operation = d
+			}
+		}
+	}
+	if operation == nil {
+		return nil
+	}
+
+	c := graphQLCost{fragments: fragments, variables: variables}
+	depth, complexity := c.selectionSet(operation.SelectionSet, 1, levelQuery)
+
+	if limit := h.opts.GraphQL.MaxDepth; limit > 0 && depth > limit {
+		return &gqlError{code: CodeQueryTooComplex, message: fmt.Sprintf("query depth %d exceeds the limit of %d", depth, limit)}
+	}
+	if limit := h.opts.GraphQL.MaxComplexity; limit > 0 && complexity > limit {
+		return &gqlError{code: CodeQueryTooComplex, message: fmt.Sprintf("query complexity %d exceeds the limit of %d", complexity, limit)}
+	}
+	return nil
+}
+
+type graphQLCost struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// selectionSet возвращает глубину и сложность набора полей уровня level.
+// Циклы фрагментов отсекаются валидацией документа до подсчёта.
+func (c graphQLCost) selectionSet(set *ast.SelectionSet, multiplier int, level selectionLevel) (depth, complexity int) {
+	if set == nil {
+		return 0, 0
+	}
+
+	for _, sel := range set.Selections {
+		var d, cx int
+		switch s := sel.(type) {
+		case *ast.Field:
+			// Интроспекция описывает схему и не обращается к сервису
+			if strings.HasPrefix(s.Name.Value, "__") {
+				continue
+			}
+			childMultiplier, childLevel := multiplier, levelNested
+			switch {
+			case level == levelQuery && s.Name.Value == "orders":
+				childMultiplier *= c.pageSize(s)
+				childLevel = levelPage
+			case level == levelQuery && s.Name.Value == "order":
+				// order(uid) всегда загружает заказ, чтобы вернуть null для несуществующего
+				cx += orderLoadCost * multiplier
+			case level == levelPage && s.Name.Value == "orders":
+				childLevel = levelPageOrder
+			case level != levelQuery && (s.Name.Value == "items" || s.Name.Value == "warnings"):
+				childMultiplier *= defaultListSize
+			}
+			var childCx int
+			d, childCx = c.selectionSet(s.SelectionSet, childMultiplier, childLevel)
+			if childLevel == levelPageOrder && c.loadsOrder(s.SelectionSet) {
+				childCx += orderLoadCost * childMultiplier
+			}
+			d++
+			cx += childCx + multiplier
+		case *ast.InlineFragment:
+			d, cx = c.selectionSet(s.SelectionSet, multiplier, level)
+		case *ast.FragmentSpread:
+			if f, ok := c.fragments[s.Name.Value]; ok {
+				d, cx = c.selectionSet(f.SelectionSet, multiplier, level)
+			}
+		}
+		depth = max(depth, d)
+		complexity += cx
+	}
+	return depth, complexity
+}
+
+// loadsOrder сообщает, запрошено ли в наборе полей Order что-то вне краткого представления.
+func (c graphQLCost) loadsOrder(set *ast.SelectionSet) bool {
+	if set == nil {
+		return false
+	}
+	for _, sel := range set.Selections {
+		switch s := sel.(type) {
+		case *ast.Field:
+			if !strings.HasPrefix(s.Name.Value, "__") && !summaryFields[s.Name.Value] {
+				return true
+			}
+		case *ast.InlineFragment:
+			if c.loadsOrder(s.SelectionSet) {
+				return true
+			}
+		case *ast.FragmentSpread:
+			if f, ok := c.fragments[s.Name.Value]; ok && c.loadsOrder(f.SelectionSet) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pageSize — размер страницы из аргумента first, литерала или переменной.
+func (c graphQLCost) pageSize(field *ast.Field) int {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			var n int
+			if _, err := fmt.Sscan(v.Value, &n); err == nil && n > 0 {
+				return n
+			}
+		case *ast.Variable:
+			if n, ok := c.variables[v.Name.Value].(float64); ok && n > 0 {
+				return int(n)
+			}
+		}
+	}
+	return defaultPageSize
+}
@@ -0,0 +1,356 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/platonso/order-viewer/internal/domain"
+	"github.com/platonso/order-viewer/internal/logging"
+)
+
+// orderRef — заказ в ответе GraphQL. Поля краткого представления берутся из списка,
+// остальные загружаются только если запрошены: заказ страницы — вместе со всей страницей
+// через batch, отдельный заказ — через OrderService.GetOrder.
+type orderRef struct {
+	uid     string
+	summary *domain.OrderSummary
+	batch   *orderBatch
+
+	once   sync.Once
+	order  *domain.Order
+	source string
+	err    error
+}
+
+// load получает заказ из кеша или базы и маскирует персональные данные.
+func (ref *orderRef) load(ctx context.Context, h *Handler) (*domain.Order, error) {
+	if ref.batch != nil {
+		ref.batch.load(ctx, h)
+	}
+	ref.once.Do(func() {
+		order, fromCache, err := h.orderService.GetOrder(ctx, ref.uid)
+		if err != nil {
+			ref.err = err
+			return
+		}
+		ref.set(ctx, h, order, fromCache)
+	})
+	return ref.order, ref.err
+}
+
+func (ref *orderRef) set(ctx context.Context, h *Handler, order *domain.Order, fromCache bool) {
+	ref.order = h.redactOrder(ctx, order)
+	ref.source = domain.SourceDatabase
+	if fromCache {
+		ref.source = domain.SourceCache
+	}
+}
+
+// orderBatch — заказы одной страницы orders. Первое обращение к полю вне краткого
+// представления загружает всю страницу одним вызовом LookupOrders: из кеша и одним запросом к бд.
+type orderBatch struct {
+	refs []*orderRef
+	once sync.Once
+}
+
+func (b *orderBatch) load(ctx context.Context, h *Handler) {
+	b.once.Do(func() {
+		uids := make([]string, 0, len(b.refs))
+		for _, ref := range b.refs {
+			uids = append(uids, ref.uid)
+		}
+
+		results, err := h.orderService.LookupOrders(ctx, uids)
+		found := make(map[string]domain.OrderLookup, len(results))
+		for _, result := range results {
+			found[result.OrderUID] = result
+		}
+
+		for _, ref := range b.refs {
+			ref.once.Do(func() {
+				result, ok := found[ref.uid]
+				switch {
+				case err != nil:
+					ref.err = err
+				case !ok || result.Status != domain.LookupFound:
+					// Заказ из списка не может исчезнуть, но страница не должна падать целиком
+					ref.err = domain.ErrOrderNotFound
+				default:
+					ref.set(ctx, h, result.Order, result.Source == domain.SourceCache)
+				}
+			})
+		}
+	})
+}
+
+// gqlError — ошибка резолвера с машиночитаемым кодом в extensions.
+type gqlError struct {
+	code    string
+	message string
+}
+
+func (e *gqlError) Error() string { return e.message }
+
+func (e *gqlError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+// toGraphQLError сопоставляет ошибку сервиса с кодом ошибки API.
+func toGraphQLError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrValidation):
+		return &gqlError{code: CodeValidationFailed, message: err.Error()}
+	case errors.Is(err, domain.ErrOrderNotFound):
+		return &gqlError{code: CodeOrderNotFound, message: "order not found"}
+	default:
+		slog.ErrorContext(ctx, "graphql resolver failed", logging.Err(err))
+		return &gqlError{code: CodeInternalError, message: "internal server error"}
+	}
+}
+
+// newGraphQLSchema описывает запросы order(uid) и orders(filter). Имена полей совпадают с JSON REST API.
+func newGraphQLSchema(h *Handler) (graphql.Schema, error) {
+	fieldIssue := graphql.NewObject(graphql.ObjectConfig{
+		Name: "FieldIssue",
+		Fields: graphql.Fields{
+			"field":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"rule":    &graphql.Field{Type: graphql.String},
+			"message": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	delivery := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Delivery",
+		Fields: graphql.Fields{
+			"name":    &graphql.Field{Type: graphql.String},
+			"phone":   &graphql.Field{Type: graphql.String},
+			"zip":     &graphql.Field{Type: graphql.String},
+			"city":    &graphql.Field{Type: graphql.String},
+			"address": &graphql.Field{Type: graphql.String},
+			"region":  &graphql.Field{Type: graphql.String},
+			"email":   &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	payment := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Payment",
+		Fields: graphql.Fields{
+			"transaction":   &graphql.Field{Type: graphql.String},
+			"request_id":    &graphql.Field{Type: graphql.String},
+			"currency":      &graphql.Field{Type: graphql.String},
+			"provider":      &graphql.Field{Type: graphql.String},
+			"amount":        &graphql.Field{Type: graphql.Int},
+			"payment_dt":    &graphql.Field{Type: graphql.Int},
+			"bank":          &graphql.Field{Type: graphql.String},
+			"delivery_cost": &graphql.Field{Type: graphql.Int},
+			"goods_total":   &graphql.Field{Type: graphql.Int},
+			"custom_fee":    &graphql.Field{Type: graphql.Int},
+		},
+	})
+
+	item := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Item",
+		Fields: graphql.Fields{
+			"chrt_id":      &graphql.Field{Type: graphql.Int},
+			"track_number": &graphql.Field{Type: graphql.String},
+			"price":        &graphql.Field{Type: graphql.Int},
+			"rid":          &graphql.Field{Type: graphql.String},
+			"name":         &graphql.Field{Type: graphql.String},
+			"sale":         &graphql.Field{Type: graphql.Int},
+			"size":         &graphql.Field{Type: graphql.String},
+			"total_price":  &graphql.Field{Type: graphql.Int},
+			"nm_id":        &graphql.Field{Type: graphql.Int},
+			"brand":        &graphql.Field{Type: graphql.String},
+			"status":       &graphql.Field{Type: graphql.Int},
+		},
+	})
+
+	// summaryField отдаёт поле из краткого представления, а без него — из полного заказа
+	summaryField := func(typ graphql.Output, fromSummary func(*domain.OrderSummary) interface{}, fromOrder func(*domain.Order) interface{}) *graphql.Field {
+		return &graphql.Field{
+			Type: typ,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				ref := p.Source.(*orderRef)
+				if ref.summary != nil {
+					return fromSummary(ref.summary), nil
+				}
+				order, err := ref.load(p.Context, h)
+				if err != nil {
+					return nil, toGraphQLError(p.Context, err)
+				}
+				return fromOrder(order), nil
+			},
+		}
+	}
+
+	// orderField загружает полный заказ и отдаёт его поле
+	orderField := func(typ graphql.Output, get func(*domain.Order) interface{}) *graphql.Field {
+		return &graphql.Field{
+			Type: typ,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				order, err := p.Source.(*orderRef).load(p.Context, h)
+				if err != nil {
+					return nil, toGraphQLError(p.Context, err)
+				}
+				return get(order), nil
+			},
+		}
+	}
+
+	order := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Order",
+		Fields: graphql.Fields{
+			"order_uid": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*orderRef).uid, nil
+				},
+			},
+			"track_number": summaryField(graphql.String,
+				func(s *domain.OrderSummary) interface{} { return s.TrackNumber },
+				func(o *domain.Order) interface{} { return o.TrackNumber }),
+			"customer_id": summaryField(graphql.String,
+				func(s *domain.OrderSummary) interface{} { return s.CustomerID },
+				func(o *domain.Order) interface{} { return o.CustomerID }),
+			"delivery_service": summaryField(graphql.String,
+				func(s *domain.OrderSummary) interface{} { return s.DeliveryService },
+				func(o *domain.Order) interface{} { return o.DeliveryService }),
+			"date_created": summaryField(graphql.DateTime,
+				func(s *domain.OrderSummary) interface{} { return s.DateCreated },
+				func(o *domain.Order) interface{} { return o.DateCreated }),
+			"entry":              orderField(graphql.String, func(o *domain.Order) interface{} { return o.Entry }),
+			"locale":             orderField(graphql.String, func(o *domain.Order) interface{} { return o.Locale }),
+			"internal_signature": orderField(graphql.String, func(o *domain.Order) interface{} { return o.InternalSignature }),
+			"shardkey":           orderField(graphql.String, func(o *domain.Order) interface{} { return o.Shardkey }),
+			"sm_id":              orderField(graphql.Int, func(o *domain.Order) interface{} { return o.SmID }),
+			"oof_shard":          orderField(graphql.String, func(o *domain.Order) interface{} { return o.OofShard }),
+			"delivery":           orderField(delivery, func(o *domain.Order) interface{} { return o.Delivery }),
+			"payment":            orderField(payment, func(o *domain.Order) interface{} { return o.Payment }),
+			"items":              orderField(graphql.NewList(graphql.NewNonNull(item)), func(o *domain.Order) interface{} { return o.Items }),
+			"warnings":           orderField(graphql.NewList(graphql.NewNonNull(fieldIssue)), func(o *domain.Order) interface{} { return o.Warnings }),
+			// source — откуда загружен заказ: cache или database
+			"source": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					ref := p.Source.(*orderRef)
+					if _, err := ref.load(p.Context, h); err != nil {
+						return nil, toGraphQLError(p.Context, err)
+					}
+					return ref.source, nil
+				},
+			},
+		},
+	})
+
+	orderPage := graphql.NewObject(graphql.ObjectConfig{
+		Name: "OrderPage",
+		Fields: graphql.Fields{
+			"orders":      &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(order)))},
+			"next_cursor": &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	orderFilter := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "OrderFilter",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"customer_id":      &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"track_number":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"delivery_service": &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"brand":            &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"from":             &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+			"to":               &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+		},
+	})
+
+	orderSort := graphql.NewEnum(graphql.EnumConfig{
+		Name: "OrderSort",
+		Values: graphql.EnumValueConfigMap{
+			"DATE_CREATED_DESC": &graphql.EnumValueConfig{Value: string(domain.SortDateDesc)},
+			"DATE_CREATED_ASC":  &graphql.EnumValueConfig{Value: string(domain.SortDateAsc)},
+			"ORDER_UID_ASC":     &graphql.EnumValueConfig{Value: string(domain.SortOrderUIDAsc)},
+			"ORDER_UID_DESC":    &graphql.EnumValueConfig{Value: string(domain.SortOrderUIDDesc)},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"order": &graphql.Field{
+				Type: order,
+				Args: graphql.FieldConfigArgument{
+					"uid": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					// Бюджет промахов общий с GET /order/{order_uid}, иначе перебор uid переехал бы сюда
+					budget := missBudgetFrom(p.Context)
+					if _, ok := budget.check(); !ok {
+						return nil, &gqlError{code: CodeRateLimited, message: "rate limit exceeded, retry later"}
+					}
+
+					ref := &orderRef{uid: p.Args["uid"].(string)}
+					// Несуществующий заказ — null без ошибки
+					if _, err := ref.load(p.Context, h); err != nil {
+						if errors.Is(err, domain.ErrOrderNotFound) {
+							budget.charge(1)
+							return nil, nil
+						}
+						return nil, toGraphQLError(p.Context, err)
+					}
+					return ref, nil
+				},
+			},
+			"orders": &graphql.Field{
+				Type: graphql.NewNonNull(orderPage),
+				Args: graphql.FieldConfigArgument{
+					"filter": &graphql.ArgumentConfig{Type: orderFilter},
+					"sort":   &graphql.ArgumentConfig{Type: orderSort},
+					"first":  &graphql.ArgumentConfig{Type: graphql.Int},
+					"after":  &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					filter := domain.OrderFilter{}
+					if f, ok := p.Args["filter"].(map[string]interface{}); ok {
+						filter.CustomerID, _ = f["customer_id"].(string)
+						filter.TrackNumber, _ = f["track_number"].(string)
+						filter.DeliveryService, _ = f["delivery_service"].(string)
+						filter.Brand, _ = f["brand"].(string)
+						if from, ok := f["from"].(time.Time); ok {
+							filter.From = from
+						}
+						if to, ok := f["to"].(time.Time); ok {
+							filter.To = to
+						}
+					}
+					if sort, ok := p.Args["sort"].(string); ok {
+						filter.Sort = domain.OrderSort(sort)
+					}
+					if first, ok := p.Args["first"].(int); ok {
+						filter.Limit = first
+					}
+					after, _ := p.Args["after"].(string)
+
+					page, err := h.orderService.ListOrders(p.Context, filter, after)
+					if err != nil {
+						return nil, toGraphQLError(p.Context, err)
+					}
+
+					batch := &orderBatch{refs: make([]*orderRef, 0, len(page.Orders))}
+					for i := range page.Orders {
+						batch.refs = append(batch.refs, &orderRef{uid: page.Orders[i].OrderUID, summary: &page.Orders[i], batch: batch})
+					}
+					result := map[string]interface{}{"orders": batch.refs}
+					if page.NextCursor != "" {
+						result["next_cursor"] = page.NextCursor
+					}
+					return result, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
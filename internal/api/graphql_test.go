@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+func TestGraphQLOrdersPageLoadsInOneBatch(t *testing.T) {
+	h, db := newTestHandler(Options{MaxBodyBytes: 1 << 20})
+	for i := 0; i < 30; i++ {
+		if err := db.Save(context.Background(), testOrder(fmt.Sprintf("order-%02d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	query := func(q string) []map[string]interface{} {
+		t.Helper()
+		body, _ := json.Marshal(graphQLRequest{Query: q})
+		rec := httptest.NewRecorder()
+		h.GraphQL(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+		}
+
+		var resp struct {
+			Data struct {
+				Orders struct {
+					Orders []map[string]interface{} `json:"orders"`
+				} `json:"orders"`
+			} `json:"data"`
+			Errors []interface{} `json:"errors"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || len(resp.Errors) > 0 {
+			t.Fatalf("unexpected response %s (%v)", rec.Body.String(), err)
+		}
+		return resp.Data.Orders.Orders
+	}
+
+	// Краткое представление не загружает заказы
+	if orders := query(`{orders(first:30){orders{order_uid customer_id}}}`); len(orders) != 30 {
+		t.Fatalf("got %d orders, want 30", len(orders))
+	}
+	if db.finds != 0 || db.batchFinds != 0 {
+		t.Fatalf("summary fields loaded orders: FindByID %d, FindByIDs %d", db.finds, db.batchFinds)
+	}
+
+	orders := query(`{orders(first:30){orders{entry delivery{city} items{name}}}}`)
+	if len(orders) != 30 {
+		t.Fatalf("got %d orders, want 30", len(orders))
+	}
+	for _, o := range orders {
+		if city := o["delivery"].(map[string]interface{})["city"]; city != "Kiryat Mozkin" {
+			t.Fatalf("delivery.city = %v", city)
+		}
+	}
+	if db.finds != 0 || db.batchFinds != 1 {
+		t.Errorf("FindByID %d, FindByIDs %d calls; want 0 and 1", db.finds, db.batchFinds)
+	}
+
+	// Загруженные заказы попадают в кеш
+	query(`{orders(first:30){orders{source}}}`)
+	if db.batchFinds != 1 {
+		t.Errorf("cached page hit the database: FindByIDs %d calls", db.batchFinds)
+	}
+}
+
+func TestGraphQLCost(t *testing.T) {
+	tests := []struct {
+		query string
+		want  int
+	}{
+		// orders + 20 × (orders + order_uid)
+		{`{orders{orders{order_uid}}}`, 1 + 20 + 20},
+		// Поля краткого представления не добавляют загрузку
+		{`{orders(first:100){orders{order_uid customer_id date_created}}}`, 1 + 100 + 300},
+		// Каждый заказ страницы загружается: + 100 × orderLoadCost
+		{`{orders(first:100){orders{entry delivery{city}}}}`, 1 + 100 + 100 + 100 + 100 + 100*orderLoadCost},
+		// Фрагмент с полем полного заказа тоже требует загрузки
+		{`{orders(first:10){orders{...F}}} fragment F on Order{locale}`, 1 + 10 + 10 + 10*orderLoadCost},
+		// order(uid) загружает заказ всегда
+		{`{order(uid:"x"){order_uid}}`, 1 + 1 + orderLoadCost},
+		{`{order(uid:"x"){items{name}}}`, 1 + orderLoadCost + 1 + 10},
+	}
+
+	for _, tt := range tests {
+		doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(tt.query)})})
+		if err != nil {
+			t.Fatalf("parse %s: %v", tt.query, err)
+		}
+
+		// Сложность равна want, если запрос проходит лимит want и не проходит want-1
+		h := &Handler{opts: Options{GraphQL: GraphQLLimits{MaxComplexity: tt.want}}}
+		if err := h.checkGraphQLLimits(doc, "", nil); err != nil {
+			t.Errorf("%s: %v, want complexity %d", tt.query, err, tt.want)
+		}
+		h.opts.GraphQL.MaxComplexity = tt.want - 1
+		if err := h.checkGraphQLLimits(doc, "", nil); err == nil {
+			t.Errorf("%s: complexity is below %d", tt.query, tt.want)
+		}
+	}
+}
@@ -1,70 +1,91 @@
 package api
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/go-chi/chi/v5"
+	"github.com/graphql-go/graphql"
 	"github.com/platonso/order-viewer/internal/domain"
 	"github.com/platonso/order-viewer/internal/service"
 	"net/http"
 	"time"
 )
 
+// orderRepresentations — представления заказа; первое используется по умолчанию.
+var orderRepresentations = []string{contentTypeJSON, contentTypeNDJSON, contentTypeCSV}
+
+// Options задаёт ограничения на разбор входящих запросов.
+type Options struct {
+	// MaxBodyBytes — предельный размер тела для запросов с одним JSON-документом
+	MaxBodyBytes int64
+	// StrictDecoding запрещает неизвестные поля во входящем JSON
+	StrictDecoding bool
+	Import         ImportLimits
+	PII            PIIOptions
+	GraphQL        GraphQLLimits
+}
+
 type Handler struct {
 	orderService *service.OrderService
+	opts         Options
+	graphql      graphql.Schema
 }
 
-func NewHandler(orderService *service.OrderService) *Handler {
-	return &Handler{orderService: orderService}
+func NewHandler(orderService *service.OrderService, opts Options) *Handler {
+	h := &Handler{
+		orderService: orderService,
+		opts:         opts,
+	}
+
+	// Схема собирается из статического описания, ошибка означает ошибку в коде
+	schema, err := newGraphQLSchema(h)
+	if err != nil {
+		panic(fmt.Sprintf("invalid graphql schema: %v", err))
+	}
+	h.graphql = schema
+
+	return h
 }
 
 func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	var order domain.Order
 
-	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if !h.decodeJSONBody(w, r, &order) {
 		return
 	}
 
 	if err := h.orderService.SaveOrder(r.Context(), &order); err != nil {
-		switch {
-		case errors.Is(err, domain.ErrValidation):
-			http.Error(w, err.Error(), http.StatusBadRequest)
-		case errors.Is(err, domain.ErrOrderAlreadyExists):
-			http.Error(w, "order already exists", http.StatusConflict)
-		default:
-			http.Error(w, "failed to save order", http.StatusInternalServerError)
-		}
+		writeError(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	_ = json.NewEncoder(w).Encode(order)
+	writeJSON(w, r, http.StatusCreated, h.redactOrder(r.Context(), &order))
 }
 
 func (h *Handler) GetOrder(w http.ResponseWriter, r *http.Request) {
 	start := time.Now() // замер начала обработки
 
+	contentType, ok := negotiate(r, orderRepresentations...)
+	if !ok {
+		writeNotAcceptable(w, r, orderRepresentations...)
+		return
+	}
+
 	orderUID := chi.URLParam(r, "order_uid")
 
 	order, fromCache, err := h.orderService.GetOrder(r.Context(), orderUID)
 	if err != nil {
-		switch {
-		case errors.Is(err, domain.ErrValidation):
-			http.Error(w, err.Error(), http.StatusBadRequest)
-		case errors.Is(err, domain.ErrOrderNotFound):
-			http.Error(w, "order not found", http.StatusNotFound)
-		default:
-			http.Error(w, "failed to fetch order", http.StatusInternalServerError)
+		if errors.Is(err, domain.ErrOrderNotFound) {
+			missBudgetFrom(r.Context()).charge(1)
 		}
+		writeError(w, r, err)
 		return
 	}
 
 	if fromCache {
-		w.Header().Set("X-Data-Source", "cache")
+		w.Header().Set("X-Data-Source", domain.SourceCache)
 	} else {
-		w.Header().Set("X-Data-Source", "database")
+		w.Header().Set("X-Data-Source", domain.SourceDatabase)
 	}
 
 	// Время ответа сервера
@@ -73,6 +94,37 @@ func (h *Handler) GetOrder(w http.ResponseWriter, r *http.Request) {
 	ms := float64(duration.Nanoseconds()) / float64(time.Millisecond)
 	w.Header().Set("X-Response-Time", fmt.Sprintf("%.3f", ms))
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(order)
+	// Маскирование зависит от учётных данных, поэтому они тоже входят в Vary
+	w.Header().Add("Vary", "Accept, Authorization, X-API-Key")
+
+	// Заказ не меняется после приёма, поэтому ETag определяется без кодирования тела,
+	// а клиент может переиспользовать сохранённую копию
+	showPII := h.canSeePII(r.Context())
+	etag := orderETag(order.OrderUID, contentType, contentType == contentTypeJSON && isPretty(r), showPII)
+	setValidators(w, etag, order.DateCreated)
+	if notModified(r, etag, order.DateCreated) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if !showPII {
+		order = h.opts.PII.Policy.Redact(order)
+	}
+
+	var body []byte
+	switch contentType {
+	case contentTypeNDJSON:
+		body, err = encodeNDJSON([]*domain.Order{order})
+	case contentTypeCSV:
+		body, err = encodeItemsCSV(order)
+	default:
+		body, err = encodeJSON(r, order)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(body)
 }
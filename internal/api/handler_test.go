@@ -0,0 +1,258 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/platonso/order-viewer/internal/domain"
+	"github.com/platonso/order-viewer/internal/metrics"
+	"github.com/platonso/order-viewer/internal/repository"
+	"github.com/platonso/order-viewer/internal/service"
+)
+
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// memDB — DBRepository в памяти для тестов обработчиков.
+type memDB struct {
+	mu     sync.Mutex
+	orders map[string]*domain.Order
+	// finds и batchFinds считают вызовы FindByID и FindByIDs
+	finds      int
+	batchFinds int
+}
+
+func newMemDB() *memDB {
+	return &memDB{orders: make(map[string]*domain.Order)}
+}
+
+func (db *memDB) Save(_ context.Context, order *domain.Order) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if _, ok := db.orders[order.OrderUID]; ok {
+		return domain.ErrOrderAlreadyExists
+	}
+	db.orders[order.OrderUID] = order
+	return nil
+}
+
+func (db *memDB) FindByID(_ context.Context, orderUID string) (*domain.Order, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.finds++
+	order, ok := db.orders[orderUID]
+	if !ok {
+		return nil, domain.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func (db *memDB) FindByIDs(_ context.Context, orderUIDs []string) (map[string]*domain.Order, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.batchFinds++
+	found := make(map[string]*domain.Order, len(orderUIDs))
+	for _, uid := range orderUIDs {
+		if order, ok := db.orders[uid]; ok {
+			found[uid] = order
+		}
+	}
+	return found, nil
+}
+
+// List возвращает все заказы по возрастанию order_uid без учёта фильтра.
+func (db *memDB) List(context.Context, domain.OrderFilter) ([]domain.OrderSummary, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	summaries := make([]domain.OrderSummary, 0, len(db.orders))
+	for _, order := range db.orders {
+		summaries = append(summaries, domain.OrderSummary{
+			OrderUID:        order.OrderUID,
+			TrackNumber:     order.TrackNumber,
+			CustomerID:      order.CustomerID,
+			DeliveryService: order.DeliveryService,
+			DateCreated:     order.DateCreated,
+			Amount:          order.Payment.Amount,
+			Currency:        order.Payment.Currency,
+			ItemsCount:      len(order.Items),
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].OrderUID < summaries[j].OrderUID })
+	return summaries, nil
+}
+
+func (db *memDB) Close() {}
+
+func testOrder(uid string) *domain.Order {
+	return &domain.Order{
+		OrderUID:    uid,
+		TrackNumber: "WBILMTESTTRACK",
+		Entry:       "WBIL",
+		Locale:      "en",
+		CustomerID:  "test",
+		DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		Delivery: domain.Delivery{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+		Payment: domain.Payment{
+			Transaction:  uid,
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       1817,
+			PaymentDt:    1637907727,
+			Bank:         "alpha",
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+		},
+		Items: []domain.Item{
+			{ChrtID: 9934930, TrackNumber: "WBILMTESTTRACK", Price: 453, RID: "ab4219087a764ae0btest", Name: "Mascaras", Sale: 30, Size: "0", TotalPrice: 317, NmID: 2389212, Brand: "Vivienne Sabo", Status: 202},
+		},
+	}
+}
+
+func newTestHandler(opts Options) (*Handler, *memDB) {
+	db := newMemDB()
+	orderService := service.NewOrderService(db, repository.NewCacheRepo(), service.ConsistencyRules{}, metrics.Nop{})
+	return NewHandler(orderService, opts), db
+}
+
+// decodeError разбирает конверт ошибки из ответа.
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder) ErrorBody {
+	t.Helper()
+	var resp ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("response is not an error envelope: %v: %s", err, rec.Body.String())
+	}
+	return resp.Error
+}
+
+func TestCreateOrderBody(t *testing.T) {
+	order, err := json.Marshal(testOrder("b563feb7b2b84b6test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	withUnknownField := `{"unknown_field":1,` + string(order[1:])
+
+	tests := []struct {
+		name       string
+		strict     bool
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{"valid", false, string(order), http.StatusCreated, ""},
+		{"too large", false, string(order) + strings.Repeat(" ", 4096), http.StatusRequestEntityTooLarge, CodeRequestTooLarge},
+		{"unknown field lenient", false, withUnknownField, http.StatusCreated, ""},
+		{"unknown field strict", true, withUnknownField, http.StatusBadRequest, CodeInvalidRequestBody},
+		{"trailing garbage", false, string(order) + `garbage`, http.StatusBadRequest, CodeInvalidRequestBody},
+		{"second value", false, string(order) + `{}`, http.StatusBadRequest, CodeInvalidRequestBody},
+		{"empty", false, "", http.StatusBadRequest, CodeInvalidRequestBody},
+		{"whitespace only", false, " \n", http.StatusBadRequest, CodeInvalidRequestBody},
+		{"malformed", false, `{"order_uid":`, http.StatusBadRequest, CodeInvalidRequestBody},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _ := newTestHandler(Options{MaxBodyBytes: int64(len(order)) + 1024, StrictDecoding: tt.strict})
+
+			req := httptest.NewRequest(http.MethodPost, "/order", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", contentTypeJSON)
+			rec := httptest.NewRecorder()
+			h.CreateOrder(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			if got := decodeError(t, rec).Code; got != tt.wantCode {
+				t.Errorf("code = %q, want %q", got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestImportOrdersJSONArray(t *testing.T) {
+	first, err := json.Marshal(testOrder("import-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := json.Marshal(testOrder("import-2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	array := "[" + string(first) + "," + string(second) + "]"
+
+	tests := []struct {
+		name        string
+		body        string
+		wantStatus  int
+		wantCreated int
+	}{
+		{"array", array, http.StatusOK, 2},
+		{"trailing whitespace", array + " \n\t", http.StatusOK, 2},
+		{"empty array", "[]", http.StatusOK, 0},
+		{"trailing garbage", array + " garbage", http.StatusBadRequest, 2},
+		{"second array", array + `[{}]`, http.StatusBadRequest, 2},
+		{"trailing object", array + `{}`, http.StatusBadRequest, 2},
+		{"unterminated", "[" + string(first) + ",", http.StatusBadRequest, 1},
+		{"too large after array", array + strings.Repeat(" ", 4096) + "x", http.StatusRequestEntityTooLarge, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _ := newTestHandler(Options{Import: ImportLimits{MaxBodyBytes: int64(len(array)) + 1024, MaxLines: 10}})
+
+			req := httptest.NewRequest(http.MethodPost, "/orders/import", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", contentTypeJSON)
+			rec := httptest.NewRecorder()
+			h.ImportOrders(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			var report importReport
+			if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+				t.Fatalf("response is not an import report: %v: %s", err, rec.Body.String())
+			}
+			if report.Created != tt.wantCreated {
+				t.Errorf("created = %d, want %d", report.Created, tt.wantCreated)
+			}
+			if (tt.wantStatus != http.StatusOK) != (report.Error != "") {
+				t.Errorf("report error = %q for status %d", report.Error, rec.Code)
+			}
+		})
+	}
+}
+
+func TestImportTimeoutScalesWithLimits(t *testing.T) {
+	small := ImportLimits{MaxBodyBytes: 1 << 20, MaxLines: 100}.timeout()
+	large := ImportLimits{MaxBodyBytes: 64 << 20, MaxLines: 10000}.timeout()
+
+	if small < importBaseTimeout || small > time.Minute {
+		t.Errorf("timeout for 1 MiB = %v, want between %v and 1m", small, importBaseTimeout)
+	}
+	// 64 MiB при 256 КиБ/с — 256 с, 10000 заказов по 10 мс — 100 с
+	if want := importBaseTimeout + 356*time.Second; large != want {
+		t.Errorf("timeout for 64 MiB = %v, want %v", large, want)
+	}
+}
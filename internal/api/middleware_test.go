@@ -0,0 +1,46 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecovererWritesErrorEnvelope(t *testing.T) {
+	handler := requestID(recoverer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/order/x", nil)
+	req.Header.Set(requestIDHeader, "test-request-1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != contentTypeJSON {
+		t.Errorf("Content-Type = %q, want %q", ct, contentTypeJSON)
+	}
+
+	body := decodeError(t, rec)
+	if body.Code != CodeInternalError {
+		t.Errorf("code = %q, want %q", body.Code, CodeInternalError)
+	}
+	if body.RequestID != "test-request-1" {
+		t.Errorf("request_id = %q, want %q", body.RequestID, "test-request-1")
+	}
+}
+
+func TestRecovererRepanicsOnAbort(t *testing.T) {
+	handler := recoverer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	defer func() {
+		if rec := recover(); rec != http.ErrAbortHandler {
+			t.Fatalf("recovered %v, want http.ErrAbortHandler", rec)
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
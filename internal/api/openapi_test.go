@@ -0,0 +1,251 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/platonso/order-viewer/internal/auth"
+	"github.com/platonso/order-viewer/internal/metrics"
+	"github.com/platonso/order-viewer/internal/ratelimit"
+)
+
+// Ключи API, с которыми собирается роутер в тестах спецификации.
+const (
+	testReaderKey = "test-reader-key"
+	testWriterKey = "test-writer-key"
+	testAdminKey  = "test-admin-key"
+)
+
+func init() {
+	// Представления без декодера в kin-openapi проверяются как строка
+	for _, contentType := range []string{contentTypeNDJSON, contentTypeEventStream, "text/html"} {
+		openapi3filter.RegisterBodyDecoder(contentType, openapi3filter.FileBodyDecoder)
+	}
+}
+
+// specTarget — роутер приложения и спецификация, которой должны соответствовать его ответы.
+type specTarget struct {
+	handler http.Handler
+	router  routers.Router
+	db      *memDB
+}
+
+func newSpecTarget(t *testing.T, limits *RateLimits) *specTarget {
+	t.Helper()
+
+	// NewRouter отдаёт статику и спецификацию относительно корня репозитория
+	t.Chdir("../..")
+
+	doc, err := openapi3.NewLoader().LoadFromFile("api/openapi/openapi.json")
+	if err != nil {
+		t.Fatalf("load spec: %v", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("invalid spec: %v", err)
+	}
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		t.Fatalf("spec router: %v", err)
+	}
+
+	authn, err := auth.NewAuthenticator(auth.Config{APIKeys: []string{
+		testReaderKey + ":reader",
+		testWriterKey + ":writer",
+		testAdminKey + ":admin",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var limiter *RateLimiter
+	if limits != nil {
+		limiter = NewRateLimiter(*limits)
+	}
+
+	h, db := newTestHandler(Options{MaxBodyBytes: 1 << 20, Import: ImportLimits{MaxBodyBytes: 1 << 20, MaxLines: 100}})
+	handler := NewRouter(h, NewAdminHandler(nil), authn, limiter, metrics.NewRegistry())
+
+	return &specTarget{handler: handler, router: router, db: db}
+}
+
+type specCase struct {
+	name       string
+	method     string
+	path       string
+	key        string
+	header     map[string]string
+	body       string
+	wantStatus int
+}
+
+// check выполняет запрос и сверяет запрос и ответ со спецификацией.
+func (st *specTarget) check(t *testing.T, tc specCase) {
+	t.Helper()
+
+	req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+	if tc.key != "" {
+		req.Header.Set("X-API-Key", tc.key)
+	}
+	for k, v := range tc.header {
+		req.Header.Set(k, v)
+	}
+
+	// Запрос со снятым контекстом нужен для потоковых ответов, которые иначе не завершатся
+	ctx, cancel := context.WithCancel(req.Context())
+	if tc.header["Accept"] == contentTypeEventStream {
+		cancel()
+	}
+	defer cancel()
+
+	rec := httptest.NewRecorder()
+	st.handler.ServeHTTP(rec, req.WithContext(ctx))
+
+	if rec.Code != tc.wantStatus {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, tc.wantStatus, rec.Body.String())
+	}
+
+	specReq := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+	specReq.Header = req.Header.Clone()
+	route, pathParams, err := st.router.FindRoute(specReq)
+	if err != nil {
+		t.Fatalf("route is not documented: %v", err)
+	}
+
+	reqInput := &openapi3filter.RequestValidationInput{
+		Request:    specReq,
+		PathParams: pathParams,
+		Route:      route,
+		Options: &openapi3filter.Options{
+			AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+			IncludeResponseStatus: true,
+		},
+	}
+	// Запросы, которые сервер должен отклонить, могут не соответствовать спецификации
+	if tc.wantStatus < http.StatusBadRequest {
+		if err := openapi3filter.ValidateRequest(context.Background(), reqInput); err != nil {
+			t.Fatalf("request does not match spec: %v", err)
+		}
+	}
+
+	respInput := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: reqInput,
+		Status:                 rec.Code,
+		Header:                 rec.Header(),
+		Body:                   io.NopCloser(bytes.NewReader(rec.Body.Bytes())),
+		Options:                reqInput.Options,
+	}
+	if err := openapi3filter.ValidateResponse(context.Background(), respInput); err != nil {
+		t.Fatalf("response does not match spec: %v\n%s", err, rec.Body.String())
+	}
+
+}
+
+func TestRouterMatchesOpenAPI(t *testing.T) {
+	st := newSpecTarget(t, nil)
+
+	stored := testOrder("b563feb7b2b84b6test")
+	if err := st.db.Save(context.Background(), stored); err != nil {
+		t.Fatal(err)
+	}
+
+	newOrder := `{"order_uid":"c1","track_number":"WBILMTESTTRACK","entry":"WBIL","locale":"en",` +
+		`"date_created":"2021-11-26T06:22:19Z",` +
+		`"delivery":{"name":"Test Testov","phone":"+9720000000","email":"test@gmail.com"},` +
+		`"payment":{"transaction":"c1","currency":"USD","amount":1817,"delivery_cost":1500,"goods_total":317},` +
+		`"items":[{"chrt_id":9934930,"price":453,"name":"Mascaras","sale":30,"total_price":317}]}`
+	importLine := strings.ReplaceAll(newOrder, `"c1"`, `"c2"`)
+
+	etag := orderETag(stored.OrderUID, contentTypeJSON, false, false)
+	orderPath := "/order/" + stored.OrderUID
+
+	tests := []specCase{
+		{name: "index", method: http.MethodGet, path: "/", wantStatus: http.StatusOK},
+		{name: "docs", method: http.MethodGet, path: "/docs", wantStatus: http.StatusOK},
+		{name: "spec", method: http.MethodGet, path: "/openapi.json", wantStatus: http.StatusOK},
+		{name: "metrics", method: http.MethodGet, path: "/metrics", key: testAdminKey, wantStatus: http.StatusOK},
+		{name: "metrics without key", method: http.MethodGet, path: "/metrics", wantStatus: http.StatusUnauthorized},
+		{name: "metrics as reader", method: http.MethodGet, path: "/metrics", key: testReaderKey, wantStatus: http.StatusForbidden},
+
+		{name: "create order", method: http.MethodPost, path: "/order", key: testWriterKey,
+			header: map[string]string{"Content-Type": contentTypeJSON}, body: newOrder, wantStatus: http.StatusCreated},
+		{name: "create duplicate", method: http.MethodPost, path: "/order", key: testWriterKey,
+			header: map[string]string{"Content-Type": contentTypeJSON}, body: newOrder, wantStatus: http.StatusConflict},
+		{name: "create invalid", method: http.MethodPost, path: "/order", key: testWriterKey,
+			header: map[string]string{"Content-Type": contentTypeJSON}, body: `{"order_uid":"c3"}`, wantStatus: http.StatusBadRequest},
+		{name: "create without key", method: http.MethodPost, path: "/order",
+			header: map[string]string{"Content-Type": contentTypeJSON}, body: newOrder, wantStatus: http.StatusUnauthorized},
+		{name: "create as reader", method: http.MethodPost, path: "/order", key: testReaderKey,
+			header: map[string]string{"Content-Type": contentTypeJSON}, body: newOrder, wantStatus: http.StatusForbidden},
+		{name: "create too large", method: http.MethodPost, path: "/order", key: testWriterKey,
+			header: map[string]string{"Content-Type": contentTypeJSON}, body: newOrder + strings.Repeat(" ", 1<<20), wantStatus: http.StatusRequestEntityTooLarge},
+
+		{name: "get json", method: http.MethodGet, path: orderPath, key: testReaderKey, wantStatus: http.StatusOK},
+		{name: "get pretty", method: http.MethodGet, path: orderPath + "?pretty", key: testAdminKey, wantStatus: http.StatusOK},
+		{name: "get ndjson", method: http.MethodGet, path: orderPath, key: testReaderKey,
+			header: map[string]string{"Accept": contentTypeNDJSON}, wantStatus: http.StatusOK},
+		{name: "get csv", method: http.MethodGet, path: orderPath, key: testReaderKey,
+			header: map[string]string{"Accept": contentTypeCSV}, wantStatus: http.StatusOK},
+		{name: "get not modified", method: http.MethodGet, path: orderPath, key: testReaderKey,
+			header: map[string]string{"If-None-Match": etag}, wantStatus: http.StatusNotModified},
+		{name: "get not acceptable", method: http.MethodGet, path: orderPath, key: testReaderKey,
+			header: map[string]string{"Accept": "application/xml"}, wantStatus: http.StatusNotAcceptable},
+		{name: "get missing", method: http.MethodGet, path: "/order/missing", key: testReaderKey, wantStatus: http.StatusNotFound},
+		{name: "get invalid uid", method: http.MethodGet, path: "/order/" + strings.Repeat("a", 37), key: testReaderKey, wantStatus: http.StatusBadRequest},
+
+		{name: "list", method: http.MethodGet, path: "/orders?limit=10", key: testReaderKey, wantStatus: http.StatusOK},
+		{name: "list ndjson", method: http.MethodGet, path: "/orders", key: testReaderKey,
+			header: map[string]string{"Accept": contentTypeNDJSON}, wantStatus: http.StatusOK},
+		{name: "list invalid limit", method: http.MethodGet, path: "/orders?limit=x", key: testReaderKey, wantStatus: http.StatusBadRequest},
+
+		{name: "lookup", method: http.MethodPost, path: "/orders/lookup", key: testReaderKey,
+			header: map[string]string{"Content-Type": contentTypeJSON},
+			body:   `{"order_uids":["` + stored.OrderUID + `","missing"]}`, wantStatus: http.StatusOK},
+
+		{name: "import ndjson", method: http.MethodPost, path: "/orders/import", key: testWriterKey,
+			header: map[string]string{"Content-Type": contentTypeNDJSON}, body: importLine + "\n{}\n", wantStatus: http.StatusOK},
+		{name: "import unsupported type", method: http.MethodPost, path: "/orders/import", key: testWriterKey,
+			header: map[string]string{"Content-Type": "text/plain"}, body: importLine, wantStatus: http.StatusUnsupportedMediaType},
+
+		{name: "stream", method: http.MethodGet, path: "/orders/stream", key: testReaderKey,
+			header: map[string]string{"Accept": contentTypeEventStream}, wantStatus: http.StatusOK},
+
+		{name: "graphql get", method: http.MethodGet, path: "/graphql?query=%7Border(uid%3A%22" + stored.OrderUID + "%22)%7Border_uid%7D%7D",
+			key: testReaderKey, wantStatus: http.StatusOK},
+		{name: "graphql post", method: http.MethodPost, path: "/graphql", key: testReaderKey,
+			header: map[string]string{"Content-Type": contentTypeJSON},
+			body:   `{"query":"{orders(first:5){orders{order_uid}}}"}`, wantStatus: http.StatusOK},
+
+		{name: "consumer status", method: http.MethodGet, path: "/admin/consumer", key: testAdminKey, wantStatus: http.StatusServiceUnavailable},
+		{name: "consumer status as reader", method: http.MethodGet, path: "/admin/consumer", key: testReaderKey, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			st.check(t, tc)
+		})
+	}
+}
+
+func TestRouterRateLimitMatchesOpenAPI(t *testing.T) {
+	st := newSpecTarget(t, &RateLimits{
+		Read:  ratelimit.Rate{Count: 1, Per: time.Hour, Burst: 1},
+		Write: ratelimit.Rate{Count: 1, Per: time.Hour, Burst: 1},
+		Miss:  ratelimit.Rate{Count: 1, Per: time.Hour, Burst: 1},
+	})
+
+	tc := specCase{method: http.MethodGet, path: "/orders", key: testReaderKey, wantStatus: http.StatusOK}
+	st.check(t, tc)
+
+	tc.wantStatus = http.StatusTooManyRequests
+	st.check(t, tc)
+}
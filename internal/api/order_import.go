@@ -0,0 +1,237 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/platonso/order-viewer/internal/domain"
+	"github.com/platonso/order-viewer/internal/logging"
+)
+
+// maxImportLineBytes — максимальная длина одной строки NDJSON.
+const maxImportLineBytes = 1 << 20
+
+// Из чего складывается таймаут импорта: тело передаётся не медленнее importMinThroughput
+// байт в секунду, а каждый заказ сохраняется не дольше importOrderTime.
+const (
+	importBaseTimeout   = 30 * time.Second
+	importMinThroughput = 256 << 10
+	importOrderTime     = 10 * time.Millisecond
+)
+
+// ImportLimits ограничивает размер тела и количество заказов в одном импорте.
+type ImportLimits struct {
+	MaxBodyBytes int64
+	MaxLines     int
+}
+
+// Статусы строк отчёта об импорте.
+const (
+	importCreated   = "created"
+	importDuplicate = "duplicate"
+	importInvalid   = "invalid"
+	importFailed    = "error"
+)
+
+type importLineResult struct {
+	// Line — номер строки NDJSON или порядковый номер элемента JSON-массива, начиная с 1
+	Line     int          `json:"line"`
+	OrderUID string       `json:"order_uid,omitempty"`
+	Status   string       `json:"status"`
+	Errors   []FieldIssue `json:"errors,omitempty"`
+}
+
+type importReport struct {
+	Total     int                `json:"total"`
+	Created   int                `json:"created"`
+	Duplicate int                `json:"duplicate"`
+	Invalid   int                `json:"invalid"`
+	Failed    int                `json:"failed"`
+	Results   []importLineResult `json:"results"`
+	// Error заполняется, если импорт прерван до конца тела запроса
+	Error string `json:"error,omitempty"`
+}
+
+// timeout — время на импорт тела размером MaxBodyBytes из MaxLines заказов.
+func (l ImportLimits) timeout() time.Duration {
+	return importBaseTimeout +
+		time.Duration(l.MaxBodyBytes)*time.Second/importMinThroughput +
+		time.Duration(l.MaxLines)*importOrderTime
+}
+
+// errImportLimit — импорт прерван из-за превышения лимитов.
+var errImportLimit = errors.New("import limit exceeded")
+
+// ImportOrders — POST /orders/import: потоковая загрузка заказов в NDJSON или JSON-массиве.
+// Тело читается по одному заказу, поэтому большие файлы не буферизуются целиком.
+func (h *Handler) ImportOrders(w http.ResponseWriter, r *http.Request) {
+	// Импорт большого файла может длиться дольше таймаутов сервера, поэтому дедлайн
+	// рассчитывается по лимитам импорта: медленный клиент не держит обработчик бесконечно
+	deadline := time.Now().Add(h.opts.Import.timeout())
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(deadline)
+	_ = rc.SetWriteDeadline(deadline)
+
+	body := http.MaxBytesReader(w, r.Body, h.opts.Import.MaxBodyBytes)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	var next func() ([]byte, int, error)
+	switch mediaType {
+	case "application/x-ndjson", "application/jsonl", "application/json-lines":
+		next = ndjsonReader(body)
+	case "application/json", "":
+		var err error
+		if next, err = jsonArrayReader(body); err != nil {
+			writeErrorCode(w, r, http.StatusBadRequest, CodeInvalidRequestBody, "request body must be a JSON array of orders")
+			return
+		}
+	default:
+		writeErrorCode(w, r, http.StatusUnsupportedMediaType, CodeUnsupportedMediaType,
+			"content type must be application/x-ndjson or application/json")
+		return
+	}
+
+	report := importReport{Results: []importLineResult{}}
+	status := http.StatusOK
+
+	for {
+		raw, line, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) || errors.Is(err, errImportLimit) {
+				status = http.StatusRequestEntityTooLarge
+			} else {
+				status = http.StatusBadRequest
+			}
+			report.Error = err.Error()
+			break
+		}
+
+		if report.Total >= h.opts.Import.MaxLines {
+			status = http.StatusRequestEntityTooLarge
+			report.Error = fmt.Sprintf("%v: at most %d orders per request", errImportLimit, h.opts.Import.MaxLines)
+			break
+		}
+
+		result := h.importOrder(r, raw, line)
+		report.Total++
+		switch result.Status {
+		case importCreated:
+			report.Created++
+		case importDuplicate:
+			report.Duplicate++
+		case importInvalid:
+			report.Invalid++
+		default:
+			report.Failed++
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	writeJSON(w, r, status, report)
+}
+
+func (h *Handler) importOrder(r *http.Request, raw []byte, line int) importLineResult {
+	result := importLineResult{Line: line}
+
+	var order domain.Order
+	if err := h.decodeJSONBytes(raw, &order); err != nil {
+		result.Status = importInvalid
+		result.Errors = []FieldIssue{{Message: "invalid JSON: " + err.Error()}}
+		return result
+	}
+	result.OrderUID = order.OrderUID
+
+	err := h.orderService.SaveOrder(r.Context(), &order)
+
+	var (
+		validationErrs domain.ValidationErrors
+		fieldErr       *domain.FieldError
+	)
+	switch {
+	case err == nil:
+		result.Status = importCreated
+	case errors.Is(err, domain.ErrOrderAlreadyExists):
+		result.Status = importDuplicate
+	case errors.As(err, &validationErrs):
+		result.Status = importInvalid
+		result.Errors = validationErrorBody(validationErrs...).Details
+	case errors.As(err, &fieldErr):
+		result.Status = importInvalid
+		result.Errors = validationErrorBody(fieldErr).Details
+	default:
+		slog.ErrorContext(r.Context(), "order import failed", "order_uid", order.OrderUID, "line", line, logging.Err(err))
+		result.Status = importFailed
+		result.Errors = []FieldIssue{{Message: "failed to save order"}}
+	}
+
+	return result
+}
+
+// ndjsonReader возвращает заказы по одному на строку, пропуская пустые строки.
+func ndjsonReader(body io.Reader) func() ([]byte, int, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineBytes)
+	line := 0
+
+	return func() ([]byte, int, error) {
+		for scanner.Scan() {
+			line++
+			if raw := bytes.TrimSpace(scanner.Bytes()); len(raw) > 0 {
+				return raw, line, nil
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			if errors.Is(err, bufio.ErrTooLong) {
+				return nil, line + 1, fmt.Errorf("%w: line %d is longer than %d bytes", errImportLimit, line+1, maxImportLineBytes)
+			}
+			return nil, line, err
+		}
+		return nil, line, io.EOF
+	}
+}
+
+// jsonArrayReader возвращает элементы JSON-массива по одному, не читая массив целиком.
+// После закрывающей скобки допускаются только пробельные символы.
+func jsonArrayReader(body io.Reader) (func() ([]byte, int, error), error) {
+	dec := json.NewDecoder(body)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, errors.New("expected JSON array")
+	}
+	index := 0
+
+	return func() ([]byte, int, error) {
+		if !dec.More() {
+			if _, err := dec.Token(); err != nil {
+				return nil, index, err
+			}
+			if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					return nil, index, err
+				}
+				return nil, index, errors.New("unexpected data after JSON array")
+			}
+			return nil, index, io.EOF
+		}
+
+		index++
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, index, err
+		}
+		return raw, index, nil
+	}, nil
+}
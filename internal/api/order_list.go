@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/platonso/order-viewer/internal/domain"
+)
+
+// ListOrders — GET /orders: поиск заказов с фильтрами и курсорной пагинацией.
+func (h *Handler) ListOrders(w http.ResponseWriter, r *http.Request) {
+	contentType, ok := negotiate(r, contentTypeJSON, contentTypeNDJSON)
+	if !ok {
+		writeNotAcceptable(w, r, contentTypeJSON, contentTypeNDJSON)
+		return
+	}
+
+	q := r.URL.Query()
+
+	filter := domain.OrderFilter{
+		CustomerID:      q.Get("customer_id"),
+		TrackNumber:     q.Get("track_number"),
+		DeliveryService: q.Get("delivery_service"),
+		Brand:           q.Get("brand"),
+		Sort:            domain.OrderSort(q.Get("sort")),
+	}
+
+	var errs domain.ValidationErrors
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			errs.Add("limit", "integer", "must be an integer")
+		}
+		filter.Limit = limit
+	}
+	if v := q.Get("from"); v != "" {
+		from, err := parseQueryTime(v)
+		if err != nil {
+			errs.Add("from", "datetime", "must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+		}
+		filter.From = from
+	}
+	if v := q.Get("to"); v != "" {
+		to, err := parseQueryTime(v)
+		if err != nil {
+			errs.Add("to", "datetime", "must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+		}
+		// Верхняя граница исключающая, поэтому дата включается в диапазон целиком
+		if len(v) == len(time.DateOnly) {
+			to = to.AddDate(0, 0, 1)
+		}
+		filter.To = to
+	}
+	if err := errs.Err(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	page, err := h.orderService.ListOrders(r.Context(), filter, q.Get("cursor"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Add("Vary", "Accept")
+
+	// В NDJSON каждый заказ идёт отдельной строкой, курсор передаётся заголовком
+	if contentType == contentTypeNDJSON {
+		body, err := encodeNDJSON(page.Orders)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if page.NextCursor != "" {
+			w.Header().Set("X-Next-Cursor", page.NextCursor)
+		}
+		w.Header().Set("Content-Type", contentTypeNDJSON)
+		_, _ = w.Write(body)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, page)
+}
+
+// parseQueryTime принимает RFC 3339 или дату; дата трактуется как начало суток UTC.
+func parseQueryTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, v)
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/platonso/order-viewer/internal/domain"
+)
+
+type lookupRequest struct {
+	OrderUIDs []string `json:"order_uids"`
+}
+
+type lookupResponse struct {
+	Results []domain.OrderLookup `json:"results"`
+}
+
+// LookupOrders — POST /orders/lookup: пакетный поиск заказов по списку uid.
+func (h *Handler) LookupOrders(w http.ResponseWriter, r *http.Request) {
+	var req lookupRequest
+	if !h.decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	results, err := h.orderService.LookupOrders(r.Context(), req.OrderUIDs)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// Каждый ненайденный uid — промах, дошедший до бд
+	misses := 0
+	for i := range results {
+		if results[i].Status == domain.LookupNotFound {
+			misses++
+		}
+		results[i].Order = h.redactOrder(r.Context(), results[i].Order)
+	}
+	missBudgetFrom(r.Context()).charge(misses)
+
+	writeJSON(w, r, http.StatusOK, lookupResponse{Results: results})
+}
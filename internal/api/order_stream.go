@@ -0,0 +1,118 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/platonso/order-viewer/internal/domain"
+	"github.com/platonso/order-viewer/internal/service"
+)
+
+const (
+	contentTypeEventStream = "text/event-stream"
+
+	// streamHeartbeat — период комментариев, не дающих прокси закрыть простаивающее соединение
+	streamHeartbeat = 15 * time.Second
+	// streamBuffer — сколько событий ждёт отправки медленному клиенту до его отключения
+	streamBuffer = 64
+	// streamWriteTimeout ограничивает запись одного события, чтобы зависший клиент не держал обработчик
+	streamWriteTimeout = 10 * time.Second
+	// streamRetry — пауза перед переподключением EventSource в миллисекундах
+	streamRetry = 3000
+)
+
+// StreamOrders — GET /orders/stream: лента новых заказов в формате Server-Sent Events.
+// Клиент, переподключившийся с Last-Event-ID, получает пропущенные события из буфера сервиса.
+func (h *Handler) StreamOrders(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	customerID := q.Get("customer_id")
+	deliveryService := q.Get("delivery_service")
+
+	// EventSource передаёт Last-Event-ID заголовком при переподключении; параметр нужен для первого запроса
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = q.Get("last_event_id")
+	}
+
+	sub := h.orderService.Subscribe(lastEventID, streamBuffer)
+	defer sub.Close()
+
+	// Поток живёт дольше таймаутов сервера; каждая запись ограничивается отдельно
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", contentTypeEventStream)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	write := func(frame []byte) bool {
+		_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if _, err := w.Write(frame); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	if !write([]byte(fmt.Sprintf("retry: %d\n\n", streamRetry))) {
+		return
+	}
+
+	// Пропущенные события уже вытеснены из буфера: клиент перезагружает список через GET /orders
+	if id, ok := sub.Reset(); ok {
+		if !write([]byte(fmt.Sprintf("id: %s\nevent: reset\ndata: {}\n\n", id))) {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if !write([]byte(": heartbeat\n\n")) {
+				return
+			}
+		case event, ok := <-sub.Events():
+			if !ok {
+				// Клиент переподключится с последним ID и получит пропущенное из буфера
+				if errors.Is(sub.Err(), service.ErrSubscriberLagged) {
+					write([]byte("event: lagged\ndata: {}\n\n"))
+				}
+				return
+			}
+			if customerID != "" && event.Order.CustomerID != customerID {
+				continue
+			}
+			if deliveryService != "" && event.Order.DeliveryService != deliveryService {
+				continue
+			}
+
+			frame, err := encodeEvent(event.ID, "order", h.redactOrder(r.Context(), event.Order))
+			if err != nil || !write(frame) {
+				return
+			}
+		}
+	}
+}
+
+// encodeEvent формирует событие SSE; JSON без переводов строк укладывается в одну строку data.
+func encodeEvent(id, name string, order *domain.Order) ([]byte, error) {
+	data, err := json.Marshal(order)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "id: %s\nevent: %s\ndata: ", id, name)
+	buf.Write(data)
+	buf.WriteString("\n\n")
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"context"
+
+	"github.com/platonso/order-viewer/internal/auth"
+	"github.com/platonso/order-viewer/internal/domain"
+	"github.com/platonso/order-viewer/internal/pii"
+)
+
+// PIIOptions задаёт маскирование персональных данных в ответах.
+type PIIOptions struct {
+	Policy pii.Policy
+	// Roles — роли, которым персональные данные отдаются без маскирования
+	Roles []auth.Role
+}
+
+// canSeePII сообщает, есть ли у вызывающей стороны доступ к персональным данным.
+func (h *Handler) canSeePII(ctx context.Context) bool {
+	principal := auth.FromContext(ctx)
+	for _, role := range h.opts.PII.Roles {
+		if principal.HasRole(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactOrder маскирует персональные данные заказа, если у вызывающей стороны нет к ним доступа.
+func (h *Handler) redactOrder(ctx context.Context, order *domain.Order) *domain.Order {
+	if h.canSeePII(ctx) {
+		return order
+	}
+	return h.opts.PII.Policy.Redact(order)
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/platonso/order-viewer/internal/auth"
+	"github.com/platonso/order-viewer/internal/ratelimit"
+)
+
+// RateLimits задаёт бюджеты запросов на клиента.
+type RateLimits struct {
+	Read  ratelimit.Rate
+	Write ratelimit.Rate
+	// Miss ограничивает запросы заказов, которых нет: они не попадают в кеш и идут в Postgres
+	Miss ratelimit.Rate
+	// Routes переопределяет бюджет отдельных маршрутов, ключ — "METHOD /pattern"
+	Routes map[string]ratelimit.Rate
+}
+
+// RateLimiter ограничивает частоту запросов по API-ключу, субъекту токена или IP клиента.
+type RateLimiter struct {
+	read   *ratelimit.Limiter
+	write  *ratelimit.Limiter
+	miss   *ratelimit.Limiter
+	routes map[string]*ratelimit.Limiter
+}
+
+func NewRateLimiter(limits RateLimits) *RateLimiter {
+	l := &RateLimiter{
+		read:   ratelimit.NewLimiter(limits.Read),
+		write:  ratelimit.NewLimiter(limits.Write),
+		miss:   ratelimit.NewLimiter(limits.Miss),
+		routes: make(map[string]*ratelimit.Limiter, len(limits.Routes)),
+	}
+	for route, rate := range limits.Routes {
+		l.routes[route] = ratelimit.NewLimiter(rate)
+	}
+	return l
+}
+
+// Limiters возвращает бюджеты чтения, записи и промахов, чтобы gRPC API расходовал их вместе с HTTP.
+func (l *RateLimiter) Limiters() (read, write, miss *ratelimit.Limiter) {
+	return l.read, l.write, l.miss
+}
+
+// limitReads и limitWrites списывают токен из бюджета маршрута или его класса.
+// Маршрут известен только после роутинга, поэтому middleware подключается внутри группы.
+func (l *RateLimiter) limitReads(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return l.limit(l.read, next)
+}
+
+func (l *RateLimiter) limitWrites(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return l.limit(l.write, next)
+}
+
+func (l *RateLimiter) limit(class *ratelimit.Limiter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limiter := class
+		if rl, ok := l.routes[r.Method+" "+chi.RouteContext(r.Context()).RoutePattern()]; ok {
+			limiter = rl
+		}
+
+		res := limiter.Allow(clientKey(r))
+		setRateLimitHeaders(w, res)
+		if !res.Allowed {
+			writeRateLimited(w, r, res)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitMisses отклоняет запросы клиента, исчерпавшего бюджет промахов, и передаёт
+// бюджет обработчику: он списывает токен за каждый ненайденный заказ.
+func (l *RateLimiter) limitMisses(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return l.trackMisses(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if res, ok := missBudgetFrom(r.Context()).check(); !ok {
+			setRateLimitHeaders(w, res)
+			writeRateLimited(w, r, res)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}))
+}
+
+// trackMisses передаёт бюджет промахов обработчику, не проверяя его заранее:
+// GraphQL проверяет бюджет перед каждым полем order, а не для запроса целиком.
+func (l *RateLimiter) trackMisses(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		budget := &missBudget{limiter: l.miss, key: clientKey(r)}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), missBudgetKey{}, budget)))
+	})
+}
+
+type missBudgetKey struct{}
+
+// missBudget — бюджет промахов клиента текущего запроса. Методы nil-бюджета ничего
+// не ограничивают, поэтому обработчики не зависят от того, включено ли ограничение.
+type missBudget struct {
+	limiter *ratelimit.Limiter
+	key     string
+}
+
+func missBudgetFrom(ctx context.Context) *missBudget {
+	budget, _ := ctx.Value(missBudgetKey{}).(*missBudget)
+	return budget
+}
+
+// check сообщает, остались ли у клиента токены промахов.
+func (b *missBudget) check() (ratelimit.Result, bool) {
+	if b == nil {
+		return ratelimit.Result{}, true
+	}
+	res := b.limiter.Peek(b.key)
+	return res, res.Allowed
+}
+
+// charge списывает n токенов за ненайденные заказы.
+func (b *missBudget) charge(n int) {
+	if b == nil || n == 0 {
+		return
+	}
+	b.limiter.Charge(b.key, n)
+}
+
+// clientKey — ключ бюджета: аутентифицированный субъект, иначе IP клиента.
+func clientKey(r *http.Request) string {
+	if p := auth.FromContext(r.Context()); p != nil && (p.Method == "api_key" || p.Method == "jwt") {
+		return p.Method + ":" + p.Subject
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+func setRateLimitHeaders(w http.ResponseWriter, res ratelimit.Result) {
+	h := w.Header()
+	h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+}
+
+func writeRateLimited(w http.ResponseWriter, r *http.Request, res ratelimit.Result) {
+	w.Header().Set("Retry-After", strconv.Itoa(max(1, ceilSeconds(res.RetryAfter))))
+	writeErrorCode(w, r, http.StatusTooManyRequests, CodeRateLimited, "rate limit exceeded, retry later")
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/platonso/order-viewer/internal/ratelimit"
+)
+
+// TestMissBudget проверяет, что промахи всех маршрутов чтения заказа по uid списываются
+// из одного бюджета и исчерпанный бюджет отклоняет GET /order/{order_uid}.
+func TestMissBudget(t *testing.T) {
+	const budget = 3
+
+	newTarget := func(t *testing.T) *specTarget {
+		st := newSpecTarget(t, &RateLimits{
+			Read:  ratelimit.Rate{Count: 1000, Per: time.Hour, Burst: 1000},
+			Write: ratelimit.Rate{Count: 1000, Per: time.Hour, Burst: 1000},
+			Miss:  ratelimit.Rate{Count: budget, Per: time.Hour, Burst: budget},
+		})
+		if err := st.db.Save(t.Context(), testOrder("stored")); err != nil {
+			t.Fatal(err)
+		}
+		return st
+	}
+	do := func(st *specTarget, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-API-Key", testReaderKey)
+		if body != "" {
+			req.Header.Set("Content-Type", contentTypeJSON)
+		}
+		rec := httptest.NewRecorder()
+		st.handler.ServeHTTP(rec, req)
+		return rec
+	}
+	assertExhausted := func(t *testing.T, st *specTarget) {
+		t.Helper()
+		if rec := do(st, http.MethodGet, "/order/stored", ""); rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("GET /order after exhausted budget: status = %d, want 429", rec.Code)
+		}
+	}
+
+	t.Run("get order", func(t *testing.T) {
+		st := newTarget(t)
+		for i := 0; i < budget; i++ {
+			if rec := do(st, http.MethodGet, "/order/missing", ""); rec.Code != http.StatusNotFound {
+				t.Fatalf("miss %d: status = %d, want 404", i, rec.Code)
+			}
+		}
+		assertExhausted(t, st)
+	})
+
+	t.Run("found orders are free", func(t *testing.T) {
+		st := newTarget(t)
+		for i := 0; i < budget*2; i++ {
+			if rec := do(st, http.MethodGet, "/order/stored", ""); rec.Code != http.StatusOK {
+				t.Fatalf("request %d: status = %d, want 200", i, rec.Code)
+			}
+		}
+		body := `{"order_uids":["stored","stored","stored"]}`
+		if rec := do(st, http.MethodPost, "/orders/lookup", body); rec.Code != http.StatusOK {
+			t.Fatalf("lookup: status = %d, want 200", rec.Code)
+		}
+		if rec := do(st, http.MethodGet, "/order/stored", ""); rec.Code != http.StatusOK {
+			t.Fatalf("budget charged for found orders: status = %d", rec.Code)
+		}
+	})
+
+	t.Run("lookup charges each missing uid", func(t *testing.T) {
+		st := newTarget(t)
+		body := `{"order_uids":["stored","missing-1","missing-2","missing-3"]}`
+		if rec := do(st, http.MethodPost, "/orders/lookup", body); rec.Code != http.StatusOK {
+			t.Fatalf("first lookup: status = %d, want 200: %s", rec.Code, rec.Body.String())
+		}
+		if rec := do(st, http.MethodPost, "/orders/lookup", `{"order_uids":["stored"]}`); rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("second lookup: status = %d, want 429", rec.Code)
+		}
+		assertExhausted(t, st)
+	})
+
+	t.Run("graphql order charges each null", func(t *testing.T) {
+		st := newTarget(t)
+		// Поля разрешаются в произвольном порядке, поэтому все uid отсутствуют,
+		// а ошибку получает то поле, до которого бюджет уже исчерпан
+		query := `{"query":"{ a: order(uid: \"missing-1\") { order_uid } b: order(uid: \"missing-2\") { order_uid } c: order(uid: \"missing-3\") { order_uid } d: order(uid: \"missing-4\") { order_uid } }"}`
+		rec := do(st, http.MethodPost, "/graphql", query)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("graphql: status = %d, want 200: %s", rec.Code, rec.Body.String())
+		}
+
+		var resp struct {
+			Data   map[string]any `json:"data"`
+			Errors []struct {
+				Extensions map[string]any `json:"extensions"`
+			} `json:"errors"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != CodeRateLimited {
+			t.Fatalf("want rate_limited error for the field after exhausted budget, got %s", rec.Body.String())
+		}
+		assertExhausted(t, st)
+	})
+}
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/klauspost/compress/zstd"
+	"github.com/platonso/order-viewer/internal/domain"
+	"github.com/platonso/order-viewer/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Поддерживаемые представления ответов.
+const (
+	contentTypeJSON   = "application/json"
+	contentTypeNDJSON = "application/x-ndjson"
+	contentTypeCSV    = "text/csv"
+)
+
+// newCompressor настраивает сжатие ответов: zstd имеет приоритет над gzip и deflate.
+func newCompressor() *middleware.Compressor {
+	compressor := middleware.NewCompressor(5,
+		contentTypeJSON,
+		contentTypeNDJSON,
+		contentTypeCSV,
+		"text/html",
+		"text/plain",
+	)
+	compressor.SetEncoder("zstd", func(w io.Writer, level int) io.Writer {
+		enc, err := zstd.NewWriter(w,
+			zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)),
+			zstd.WithEncoderConcurrency(1),
+		)
+		if err != nil {
+			return nil
+		}
+		return enc
+	})
+	return compressor
+}
+
+// negotiate выбирает представление из offers по заголовку Accept с учётом q-весов.
+// Без Accept возвращается первое из offers; если ничего не подходит — ok == false.
+func negotiate(r *http.Request, offers ...string) (string, bool) {
+	header := r.Header.Get("Accept")
+	if header == "" {
+		return offers[0], true
+	}
+
+	type accepted struct {
+		mediaType string
+		q         float64
+		order     int
+	}
+
+	var ranges []accepted
+	for i, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		ranges = append(ranges, accepted{mediaType: mediaType, q: q, order: i})
+	}
+
+	// Более точные диапазоны важнее шаблонов при равном весе
+	specificity := func(mediaType string) int {
+		switch {
+		case mediaType == "*/*":
+			return 0
+		case strings.HasSuffix(mediaType, "/*"):
+			return 1
+		default:
+			return 2
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].q != ranges[j].q {
+			return ranges[i].q > ranges[j].q
+		}
+		return specificity(ranges[i].mediaType) > specificity(ranges[j].mediaType)
+	})
+
+	for _, ar := range ranges {
+		if ar.q <= 0 {
+			continue
+		}
+		for _, offer := range offers {
+			if mediaMatches(ar.mediaType, offer) {
+				return offer, true
+			}
+		}
+	}
+	return "", false
+}
+
+func mediaMatches(pattern, offer string) bool {
+	if pattern == "*/*" || pattern == offer {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.HasPrefix(offer, prefix+"/")
+	}
+	return false
+}
+
+// writeNotAcceptable сообщает, какие представления поддерживает ресурс.
+func writeNotAcceptable(w http.ResponseWriter, r *http.Request, offers ...string) {
+	writeErrorCode(w, r, http.StatusNotAcceptable, CodeNotAcceptable,
+		"supported representations: "+strings.Join(offers, ", "))
+}
+
+// encodeJSON кодирует v в JSON; параметр ?pretty включает форматирование с отступами.
+func encodeJSON(r *http.Request, v any) ([]byte, error) {
+	_, span := tracer.Start(r.Context(), "encode json")
+	defer span.End()
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	if isPretty(r) {
+		enc.SetIndent("", "  ")
+	}
+	if err := enc.Encode(v); err != nil {
+		tracing.Fail(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("response.size", buf.Len()))
+	return buf.Bytes(), nil
+}
+
+func isPretty(r *http.Request) bool {
+	v, ok := r.URL.Query()["pretty"]
+	if !ok {
+		return false
+	}
+	if len(v) == 0 || v[0] == "" {
+		return true
+	}
+	pretty, _ := strconv.ParseBool(v[0])
+	return pretty
+}
+
+// writeJSON отправляет v как JSON с указанным статусом.
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	body, err := encodeJSON(r, v)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// encodeNDJSON кодирует каждый элемент отдельной строкой JSON.
+func encodeNDJSON[T any](values []T) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, v := range values {
+		if err := enc.Encode(v); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+var itemCSVHeader = []string{
+	"order_uid", "chrt_id", "track_number", "price", "rid", "name",
+	"sale", "size", "total_price", "nm_id", "brand", "status",
+}
+
+// encodeItemsCSV выгружает товары заказа в CSV, по строке на товар.
+func encodeItemsCSV(order *domain.Order) ([]byte, error) {
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+
+	if err := cw.Write(itemCSVHeader); err != nil {
+		return nil, err
+	}
+	for _, item := range order.Items {
+		record := []string{
+			order.OrderUID,
+			strconv.Itoa(item.ChrtID),
+			item.TrackNumber,
+			strconv.Itoa(item.Price),
+			item.RID,
+			item.Name,
+			strconv.Itoa(item.Sale),
+			item.Size,
+			strconv.Itoa(item.TotalPrice),
+			strconv.Itoa(item.NmID),
+			item.Brand,
+			strconv.Itoa(item.Status),
+		}
+		if err := cw.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -6,10 +6,22 @@ import (
 	"path/filepath"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/platonso/order-viewer/internal/auth"
+	"github.com/platonso/order-viewer/internal/metrics"
 )
 
-func NewRouter(h *Handler) http.Handler {
+// NewRouter собирает маршруты API. Выключенную аутентификацию задаёт auth.NewDisabledAuthenticator;
+// limiter == nil отключает ограничение частоты запросов, registry == nil — метрики.
+func NewRouter(h *Handler, admin *AdminHandler, authn *auth.Authenticator, limiter *RateLimiter, registry *metrics.Registry) http.Handler {
 	r := chi.NewRouter()
+	r.Use(requestID)
+	r.Use(traceRequests)
+	r.Use(accessLog)
+	if registry != nil {
+		r.Use(instrument(registry))
+	}
+	r.Use(recoverer)
+	r.Use(newCompressor().Handler)
 
 	// Находим абсолютный путь к папке web
 	wd, err := os.Getwd()
@@ -17,15 +29,56 @@ func NewRouter(h *Handler) http.Handler {
 		panic(err)
 	}
 	webDir := filepath.Join(wd, "internal/web")
+	specFile := filepath.Join(wd, "api/openapi/openapi.json")
 
 	// Frontend
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		http.ServeFile(w, r, filepath.Join(webDir, "index.html"))
 	})
 
+	// Документация API
+	r.Get("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentTypeJSON)
+		http.ServeFile(w, r, specFile)
+	})
+	r.Get("/docs", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, filepath.Join(webDir, "docs.html"))
+	})
+
 	// Endpoints
-	r.Get("/order/{order_uid}", h.GetOrder)
-	r.Post("/order", h.CreateOrder)
+	r.Group(func(r chi.Router) {
+		r.Use(authenticate(authn))
+
+		r.Group(func(r chi.Router) {
+			r.Use(requireRole(auth.RoleReader))
+			r.Use(limiter.limitReads)
+			r.With(limiter.limitMisses).Get("/order/{order_uid}", h.GetOrder)
+			r.Get("/orders", h.ListOrders)
+			r.With(limiter.limitMisses).Post("/orders/lookup", h.LookupOrders)
+			r.Get("/orders/stream", h.StreamOrders)
+			r.With(limiter.trackMisses).Get("/graphql", h.GraphQL)
+			r.With(limiter.trackMisses).Post("/graphql", h.GraphQL)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(requireRole(auth.RoleWriter))
+			r.Use(limiter.limitWrites)
+			r.Post("/order", h.CreateOrder)
+			r.Post("/orders/import", h.ImportOrders)
+		})
+	})
+
+	// Служебные эндпоинты
+	r.Route("/admin", func(r chi.Router) {
+		r.Use(authenticate(authn))
+		r.Use(requireRole(auth.RoleAdmin))
+		r.Get("/consumer", admin.ConsumerStatus)
+	})
+
+	// Метрики для Prometheus раскрывают маршруты и нагрузку, поэтому доступны только admin
+	if registry != nil {
+		r.With(authenticate(authn), requireRole(auth.RoleAdmin)).Method(http.MethodGet, "/metrics", metrics.Handler(registry))
+	}
 
 	return r
 }
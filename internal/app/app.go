@@ -2,25 +2,40 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"strings"
+	"sync"
 
 	"github.com/platonso/order-viewer/internal/api"
+	"github.com/platonso/order-viewer/internal/auth"
 	"github.com/platonso/order-viewer/internal/config"
+	"github.com/platonso/order-viewer/internal/grpcapi"
 	"github.com/platonso/order-viewer/internal/kafka"
+	"github.com/platonso/order-viewer/internal/logging"
+	"github.com/platonso/order-viewer/internal/metrics"
+	"github.com/platonso/order-viewer/internal/pii"
+	"github.com/platonso/order-viewer/internal/ratelimit"
 	"github.com/platonso/order-viewer/internal/repository"
 	"github.com/platonso/order-viewer/internal/service"
+	"google.golang.org/grpc"
 )
 
 type Application struct {
-	Config *config.Config
-	DB     repository.DBRepository
-	Cache  repository.CacheRepository
+	Config  *config.Config
+	DB      repository.DBRepository
+	Cache   repository.CacheRepository
+	Outbox  repository.OutboxRepository
+	Metrics *metrics.Registry
 }
 
 func NewApp(ctx context.Context, cfg *config.Config) (*Application, error) {
-	postgresRepo, err := repository.NewPostgresRepo(ctx, cfg.GetConnStr())
+	registry := metrics.NewRegistry()
+
+	postgresRepo, err := repository.NewPostgresRepo(ctx, cfg.GetConnStr(), registry)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create order repository: %w", err)
 	}
@@ -28,28 +43,254 @@ func NewApp(ctx context.Context, cfg *config.Config) (*Application, error) {
 	cacheRepo := repository.NewCacheRepo()
 
 	return &Application{
-		Config: cfg,
-		DB:     postgresRepo,
-		Cache:  cacheRepo,
+		Config:  cfg,
+		DB:      postgresRepo,
+		Cache:   cacheRepo,
+		Outbox:  postgresRepo,
+		Metrics: registry,
 	}, nil
 }
 
 func (app *Application) Run(ctx context.Context) error {
-	orderService := service.NewOrderService(app.DB, app.Cache)
+	rules, err := service.NewConsistencyRules(map[string]string{
+		service.RulePaymentAmount:      app.Config.RulePaymentAmount,
+		service.RuleGoodsTotal:         app.Config.RuleGoodsTotal,
+		service.RuleItemTotalPrice:     app.Config.RuleItemTotalPrice,
+		service.RuleItemTrackNumber:    app.Config.RuleItemTrackNumber,
+		service.RulePaymentTransaction: app.Config.RulePaymentTransaction,
+	})
+	if err != nil {
+		return fmt.Errorf("invalid consistency rules: %w", err)
+	}
+
+	piiOptions, err := newPIIOptions(app.Config)
+	if err != nil {
+		return fmt.Errorf("invalid pii config: %w", err)
+	}
+
+	orderService := service.NewOrderService(app.DB, app.Cache, rules, app.Metrics)
+
+	handler := api.NewHandler(orderService, api.Options{
+		MaxBodyBytes:   app.Config.HTTPMaxBodyBytes,
+		StrictDecoding: app.Config.HTTPStrictDecoding,
+		Import: api.ImportLimits{
+			MaxBodyBytes: app.Config.ImportMaxBodyBytes,
+			MaxLines:     app.Config.ImportMaxLines,
+		},
+		PII: piiOptions,
+		GraphQL: api.GraphQLLimits{
+			MaxDepth:      app.Config.GraphQLMaxDepth,
+			MaxComplexity: app.Config.GraphQLMaxComplexity,
+		},
+	})
+
+	var authn *auth.Authenticator
+	if app.Config.AuthEnabled {
+		authn, err = auth.NewAuthenticator(auth.Config{
+			APIKeys:       app.Config.AuthAPIKeys,
+			JWKSFile:      app.Config.AuthJWKSFile,
+			Issuer:        app.Config.AuthJWTIssuer,
+			Audience:      app.Config.AuthJWTAudience,
+			RolesClaim:    app.Config.AuthJWTRolesClaim,
+			AnonymousRole: app.Config.AuthAnonymousRole,
+		})
+	} else {
+		authn, err = auth.NewDisabledAuthenticator(app.Config.AuthAnonymousRole)
+		slog.Warn("authentication is disabled, every caller gets the anonymous role")
+	}
+	if err != nil {
+		return fmt.Errorf("invalid auth config: %w", err)
+	}
+
+	var limiter *api.RateLimiter
+	if app.Config.RateLimitEnabled {
+		limits, err := newRateLimits(app.Config)
+		if err != nil {
+			return fmt.Errorf("invalid rate limit config: %w", err)
+		}
+		limiter = api.NewRateLimiter(limits)
+	}
+
+	// Порт gRPC занимается до запуска фоновых задач, чтобы ошибка не оставила их работать после выхода из Run
+	var grpcLis net.Listener
+	if app.Config.GRPCEnabled {
+		grpcLis, err = net.Listen("tcp", ":"+app.Config.GRPCPort)
+		if err != nil {
+			return fmt.Errorf("failed to listen grpc port: %w", err)
+		}
+	}
 
-	handler := api.NewHandler(orderService)
-	router := api.NewRouter(handler)
+	// Консьюмер и outbox relay останавливаются вместе с серверами, в том числе после ошибки сервера
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var background sync.WaitGroup
 
-	if err := kafka.StartConsumer(ctx, app.Config, orderService); err != nil {
-		log.Printf("failed to start kafka consumer: %v", err)
+	consumer, err := kafka.StartConsumer(ctx, app.Config, orderService, app.Metrics, &background)
+	if err != nil {
+		slog.Error("failed to start kafka consumer", logging.Err(err))
+	}
+
+	if app.Config.OutboxEnabled {
+		if err := kafka.StartOutboxRelay(ctx, app.Config, app.Outbox, &background); err != nil {
+			slog.Error("failed to start outbox relay", logging.Err(err))
+		}
+	}
+
+	adminHandler := api.NewAdminHandler(consumer)
+	router := api.NewRouter(handler, adminHandler, authn, limiter, app.Metrics)
+
+	serveErr := make(chan error, 2)
+
+	var grpcServer *grpc.Server
+	if app.Config.GRPCEnabled {
+		grpcOpts := grpcapi.Options{
+			PII:      piiOptions.Policy,
+			PIIRoles: piiOptions.Roles,
+			Metrics:  app.Metrics,
+		}
+		if limiter != nil {
+			read, write, miss := limiter.Limiters()
+			grpcOpts.RateLimits = &grpcapi.RateLimits{Read: read, Write: write, Miss: miss}
+		}
+		grpcServer = grpcapi.NewServer(orderService, authn, grpcOpts)
+
+		go func() {
+			slog.Info("grpc server is running", "port", app.Config.GRPCPort)
+			if err := grpcServer.Serve(grpcLis); err != nil {
+				serveErr <- fmt.Errorf("grpc server: %w", err)
+			}
+		}()
 	}
 
 	srv := &http.Server{
-		Addr:    ":" + app.Config.Port,
-		Handler: router,
+		Addr:              ":" + app.Config.Port,
+		Handler:           router,
+		ReadTimeout:       app.Config.HTTPReadTimeout,
+		ReadHeaderTimeout: app.Config.HTTPReadHeaderTimeout,
+		WriteTimeout:      app.Config.HTTPWriteTimeout,
+		IdleTimeout:       app.Config.HTTPIdleTimeout,
+	}
+
+	go func() {
+		slog.Info("http server is running", "port", app.Config.Port)
+		if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- fmt.Errorf("http server: %w", err)
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		slog.Info("shutting down")
+	case err = <-serveErr:
+	}
+
+	cancel()
+	// Потоки SSE и WatchOrders не завершаются сами, их закрывает сервис
+	orderService.Stop()
+	shutdownErr := app.shutdown(srv, grpcServer, &background)
+	if err != nil {
+		return err
+	}
+	return shutdownErr
+}
+
+// shutdown останавливает HTTP- и gRPC-серверы и ждёт фоновые задачи. Активные запросы и
+// начатые транзакции консьюмера и outbox relay дорабатывают не дольше ShutdownTimeout,
+// после чего main закрывает пул бд и выгружает спаны.
+func (app *Application) shutdown(srv *http.Server, grpcServer *grpc.Server, background *sync.WaitGroup) error {
+	ctx, cancel := context.WithTimeout(context.Background(), app.Config.ShutdownTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	if grpcServer != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stopped := make(chan struct{})
+			go func() {
+				grpcServer.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+			case <-ctx.Done():
+				grpcServer.Stop()
+			}
+		}()
+	}
+
+	err := srv.Shutdown(ctx)
+	if err != nil {
+		srv.Close()
+		err = fmt.Errorf("http server shutdown: %w", err)
+	}
+	wg.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		background.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		if err == nil {
+			err = errors.New("kafka consumer and outbox relay did not stop in time")
+		}
+	}
+	return err
+}
+
+func newPIIOptions(cfg *config.Config) (api.PIIOptions, error) {
+	policy, err := pii.NewPolicy(map[string]string{
+		pii.FieldDeliveryName:    cfg.PIIMaskName,
+		pii.FieldDeliveryPhone:   cfg.PIIMaskPhone,
+		pii.FieldDeliveryAddress: cfg.PIIMaskAddress,
+		pii.FieldDeliveryEmail:   cfg.PIIMaskEmail,
+	})
+	if err != nil {
+		return api.PIIOptions{}, err
 	}
 
-	log.Printf("Server is running on port %s", app.Config.Port)
+	roles := make([]auth.Role, 0, len(cfg.PIIRoles))
+	for _, r := range cfg.PIIRoles {
+		role, err := auth.ParseRole(r)
+		if err != nil {
+			return api.PIIOptions{}, err
+		}
+		roles = append(roles, role)
+	}
+
+	return api.PIIOptions{Policy: policy, Roles: roles}, nil
+}
+
+func newRateLimits(cfg *config.Config) (api.RateLimits, error) {
+	var (
+		limits api.RateLimits
+		err    error
+	)
+	if limits.Read, err = ratelimit.ParseRate(cfg.RateLimitRead); err != nil {
+		return limits, err
+	}
+	if limits.Write, err = ratelimit.ParseRate(cfg.RateLimitWrite); err != nil {
+		return limits, err
+	}
+	if limits.Miss, err = ratelimit.ParseRate(cfg.RateLimitMiss); err != nil {
+		return limits, err
+	}
+
+	limits.Routes = make(map[string]ratelimit.Rate, len(cfg.RateLimitRoutes))
+	for _, entry := range cfg.RateLimitRoutes {
+		route, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return limits, fmt.Errorf("invalid route limit %q (expected METHOD /pattern=rate)", entry)
+		}
+		rate, err := ratelimit.ParseRate(spec)
+		if err != nil {
+			return limits, err
+		}
+		limits.Routes[strings.Join(strings.Fields(route), " ")] = rate
+	}
 
-	return srv.ListenAndServe()
+	return limits, nil
 }
@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Role — роль вызывающей стороны. admin включает права всех остальных ролей.
+type Role string
+
+const (
+	RoleReader Role = "reader"
+	RoleWriter Role = "writer"
+	RoleAdmin  Role = "admin"
+)
+
+var (
+	ErrUnauthenticated = errors.New("authentication required")
+	ErrInvalidToken    = errors.New("invalid credentials")
+)
+
+func ParseRole(s string) (Role, error) {
+	switch r := Role(strings.ToLower(strings.TrimSpace(s))); r {
+	case RoleReader, RoleWriter, RoleAdmin:
+		return r, nil
+	default:
+		return "", fmt.Errorf("unknown role %q", s)
+	}
+}
+
+// Principal — аутентифицированная вызывающая сторона.
+type Principal struct {
+	Subject string
+	Roles   []Role
+	// Method — способ аутентификации: api_key, jwt или anonymous
+	Method string
+}
+
+func (p *Principal) HasRole(role Role) bool {
+	if p == nil {
+		return false
+	}
+	for _, r := range p.Roles {
+		if r == role || r == RoleAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext возвращает вызывающую сторону или nil, если запрос не аутентифицирован.
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// Config — настройки аутентификации.
+type Config struct {
+	// APIKeys задаёт статические ключи в формате key:role[|role...]
+	APIKeys []string
+	// JWKSFile — путь к локальному JWKS с ключами для HS256 (oct) и RS256 (RSA)
+	JWKSFile   string
+	Issuer     string
+	Audience   string
+	RolesClaim string
+	// AnonymousRole назначается запросам без учётных данных; пустое значение запрещает анонимный доступ
+	AnonymousRole string
+}
+
+type apiKey struct {
+	key       []byte
+	principal *Principal
+}
+
+// Authenticator проверяет API-ключи и JWT.
+type Authenticator struct {
+	apiKeys   []apiKey
+	jwt       *jwtVerifier // nil, если JWKS не настроен
+	anonymous *Principal
+	// disabled — учётные данные не проверяются, каждый запрос получает anonymous
+	disabled bool
+}
+
+func NewAuthenticator(cfg Config) (*Authenticator, error) {
+	a := &Authenticator{}
+
+	for i, entry := range cfg.APIKeys {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, rolesPart, ok := strings.Cut(entry, ":")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("api key #%d: expected key:role", i+1)
+		}
+		roles, err := parseRoles(strings.Split(rolesPart, "|"))
+		if err != nil {
+			return nil, fmt.Errorf("api key #%d: %w", i+1, err)
+		}
+		a.apiKeys = append(a.apiKeys, apiKey{
+			key: []byte(key),
+			principal: &Principal{
+				Subject: fmt.Sprintf("api-key-%d", i+1),
+				Roles:   roles,
+				Method:  "api_key",
+			},
+		})
+	}
+
+	if cfg.JWKSFile != "" {
+		verifier, err := newJWTVerifier(cfg)
+		if err != nil {
+			return nil, err
+		}
+		a.jwt = verifier
+	}
+
+	// Без ключей и JWKS получить роли writer и admin невозможно, это ошибка конфигурации
+	if len(a.apiKeys) == 0 && a.jwt == nil {
+		return nil, errors.New("no api keys or jwks file configured")
+	}
+
+	if cfg.AnonymousRole != "" {
+		role, err := ParseRole(cfg.AnonymousRole)
+		if err != nil {
+			return nil, fmt.Errorf("anonymous role: %w", err)
+		}
+		a.anonymous = &Principal{Subject: "anonymous", Roles: []Role{role}, Method: "anonymous"}
+	}
+
+	return a, nil
+}
+
+// NewDisabledAuthenticator выключает аутентификацию: учётные данные не проверяются,
+// и каждый запрос получает anonymousRole, по умолчанию reader. Права writer и admin
+// без аутентификации выдаются только явно через anonymousRole.
+func NewDisabledAuthenticator(anonymousRole string) (*Authenticator, error) {
+	if anonymousRole == "" {
+		anonymousRole = string(RoleReader)
+	}
+	role, err := ParseRole(anonymousRole)
+	if err != nil {
+		return nil, fmt.Errorf("anonymous role: %w", err)
+	}
+	return &Authenticator{
+		anonymous: &Principal{Subject: "anonymous", Roles: []Role{role}, Method: "anonymous"},
+		disabled:  true,
+	}, nil
+}
+
+// AuthenticateAPIKey сравнивает ключ со всеми настроенными за постоянное время.
+func (a *Authenticator) AuthenticateAPIKey(key string) (*Principal, error) {
+	if a.disabled {
+		return a.anonymous, nil
+	}
+
+	var found *Principal
+	for _, k := range a.apiKeys {
+		if subtle.ConstantTimeCompare(k.key, []byte(key)) == 1 {
+			found = k.principal
+		}
+	}
+	if found == nil {
+		return nil, ErrInvalidToken
+	}
+	return found, nil
+}
+
+func (a *Authenticator) AuthenticateToken(token string) (*Principal, error) {
+	if a.disabled {
+		return a.anonymous, nil
+	}
+	if a.jwt == nil {
+		return nil, ErrInvalidToken
+	}
+	return a.jwt.verify(token)
+}
+
+// Anonymous возвращает роль для запросов без учётных данных или ErrUnauthenticated.
+func (a *Authenticator) Anonymous() (*Principal, error) {
+	if a.anonymous == nil {
+		return nil, ErrUnauthenticated
+	}
+	return a.anonymous, nil
+}
+
+func parseRoles(values []string) ([]Role, error) {
+	roles := make([]Role, 0, len(values))
+	for _, v := range values {
+		if strings.TrimSpace(v) == "" {
+			continue
+		}
+		role, err := ParseRole(v)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	if len(roles) == 0 {
+		return nil, errors.New("at least one role is required")
+	}
+	return roles, nil
+}
@@ -0,0 +1,301 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestNewAuthenticatorConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"api keys", Config{APIKeys: []string{"k1:reader", "k2:writer|admin"}}, false},
+		{"blank entries skipped", Config{APIKeys: []string{"", " k1:reader "}}, false},
+		{"no credentials", Config{}, true},
+		{"only anonymous role", Config{AnonymousRole: "reader"}, true},
+		{"missing role", Config{APIKeys: []string{"k1"}}, true},
+		{"empty key", Config{APIKeys: []string{":reader"}}, true},
+		{"unknown role", Config{APIKeys: []string{"k1:owner"}}, true},
+		{"empty role list", Config{APIKeys: []string{"k1:|"}}, true},
+		{"unknown anonymous role", Config{APIKeys: []string{"k1:reader"}, AnonymousRole: "guest"}, true},
+		{"missing jwks file", Config{JWKSFile: filepath.Join(t.TempDir(), "missing.json")}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAuthenticator(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewAuthenticator() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	a, err := NewAuthenticator(Config{APIKeys: []string{"reader-key:reader", "ops-key:writer|reader"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := a.AuthenticateAPIKey("ops-key")
+	if err != nil {
+		t.Fatalf("valid key rejected: %v", err)
+	}
+	if p.Method != "api_key" || p.Subject != "api-key-2" {
+		t.Errorf("principal = %+v, want api_key api-key-2", p)
+	}
+	if !p.HasRole(RoleWriter) || !p.HasRole(RoleReader) || p.HasRole(RoleAdmin) {
+		t.Errorf("roles = %v, want writer and reader without admin", p.Roles)
+	}
+
+	for _, key := range []string{"", "unknown", "reader-key ", "reader"} {
+		if _, err := a.AuthenticateAPIKey(key); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("AuthenticateAPIKey(%q) error = %v, want ErrInvalidToken", key, err)
+		}
+	}
+
+	if _, err := a.AuthenticateToken("token"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("token without JWKS: error = %v, want ErrInvalidToken", err)
+	}
+	if _, err := a.Anonymous(); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("anonymous without role: error = %v, want ErrUnauthenticated", err)
+	}
+}
+
+func TestAnonymousRole(t *testing.T) {
+	a, err := NewAuthenticator(Config{APIKeys: []string{"k:admin"}, AnonymousRole: "Reader"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := a.Anonymous()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Method != "anonymous" || !p.HasRole(RoleReader) || p.HasRole(RoleWriter) {
+		t.Errorf("anonymous principal = %+v, want reader only", p)
+	}
+}
+
+func TestDisabledAuthenticator(t *testing.T) {
+	a, err := NewDisabledAuthenticator("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Учётные данные не проверяются и не повышают права
+	for name, authenticate := range map[string]func() (*Principal, error){
+		"no credentials": a.Anonymous,
+		"api key":        func() (*Principal, error) { return a.AuthenticateAPIKey("anything") },
+		"token":          func() (*Principal, error) { return a.AuthenticateToken("anything") },
+	} {
+		p, err := authenticate()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !p.HasRole(RoleReader) || p.HasRole(RoleWriter) || p.HasRole(RoleAdmin) {
+			t.Errorf("%s: roles = %v, want reader only", name, p.Roles)
+		}
+	}
+
+	if _, err := NewDisabledAuthenticator("root"); err == nil {
+		t.Error("unknown anonymous role accepted")
+	}
+}
+
+func TestHasRole(t *testing.T) {
+	tests := []struct {
+		roles []Role
+		role  Role
+		want  bool
+	}{
+		{[]Role{RoleReader}, RoleReader, true},
+		{[]Role{RoleReader}, RoleWriter, false},
+		{[]Role{RoleReader}, RoleAdmin, false},
+		{[]Role{RoleWriter}, RoleReader, false},
+		{[]Role{RoleAdmin}, RoleReader, true},
+		{[]Role{RoleAdmin}, RoleWriter, true},
+		{nil, RoleReader, false},
+	}
+	for _, tt := range tests {
+		p := &Principal{Roles: tt.roles}
+		if got := p.HasRole(tt.role); got != tt.want {
+			t.Errorf("%v.HasRole(%s) = %v, want %v", tt.roles, tt.role, got, tt.want)
+		}
+	}
+
+	var p *Principal
+	if p.HasRole(RoleReader) {
+		t.Error("nil principal has a role")
+	}
+}
+
+// jwtFixture — JWKS с HMAC- и RSA-ключом во временном файле.
+type jwtFixture struct {
+	file      string
+	secret    []byte
+	rsaKey    *rsa.PrivateKey
+	otherRSA  *rsa.PrivateKey
+	issuer    string
+	audience  string
+	expiresAt time.Time
+}
+
+func newJWTFixture(t *testing.T) *jwtFixture {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherRSA, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &jwtFixture{
+		secret:    []byte("0123456789abcdef0123456789abcdef"),
+		rsaKey:    rsaKey,
+		otherRSA:  otherRSA,
+		issuer:    "https://auth.example.com",
+		audience:  "order-viewer",
+		expiresAt: time.Now().Add(time.Hour),
+	}
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwks := map[string]any{"keys": []map[string]string{
+		{"kty": "oct", "kid": "hmac-1", "use": "sig", "k": b64(f.secret)},
+		{"kty": "RSA", "kid": "rsa-1", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+		// Ключ шифрования не используется для подписи
+		{"kty": "oct", "kid": "enc-1", "use": "enc", "k": b64([]byte("encryption-key"))},
+	}}
+	data, err := json.Marshal(jwks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.file = filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(f.file, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func (f *jwtFixture) claims(roles any) jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "user-1",
+		"iss":   f.issuer,
+		"aud":   f.audience,
+		"exp":   f.expiresAt.Unix(),
+		"roles": roles,
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, claims jwt.MapClaims, key any) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	s, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestAuthenticateToken(t *testing.T) {
+	f := newJWTFixture(t)
+	a, err := NewAuthenticator(Config{JWKSFile: f.file, Issuer: f.issuer, Audience: f.audience})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withClaim := func(key string, value any) jwt.MapClaims {
+		c := f.claims([]any{"reader"})
+		if value == nil {
+			delete(c, key)
+		} else {
+			c[key] = value
+		}
+		return c
+	}
+
+	tests := []struct {
+		name      string
+		token     string
+		wantRoles []Role
+	}{
+		{"hs256", sign(t, jwt.SigningMethodHS256, "hmac-1", f.claims([]any{"reader"}), f.secret), []Role{RoleReader}},
+		{"rs256", sign(t, jwt.SigningMethodRS256, "rsa-1", f.claims([]any{"writer", "admin"}), f.rsaKey), []Role{RoleWriter, RoleAdmin}},
+		{"rs256 without kid", sign(t, jwt.SigningMethodRS256, "", f.claims([]any{"reader"}), f.rsaKey), []Role{RoleReader}},
+		{"roles as string", sign(t, jwt.SigningMethodHS256, "hmac-1", f.claims("reader writer"), f.secret), []Role{RoleReader, RoleWriter}},
+
+		{"wrong hmac secret", sign(t, jwt.SigningMethodHS256, "hmac-1", f.claims([]any{"reader"}), []byte("wrong-secret-wrong-secret-wrong!")), nil},
+		{"wrong rsa key", sign(t, jwt.SigningMethodRS256, "rsa-1", f.claims([]any{"reader"}), f.otherRSA), nil},
+		{"unknown kid", sign(t, jwt.SigningMethodHS256, "hmac-2", f.claims([]any{"reader"}), f.secret), nil},
+		{"encryption key", sign(t, jwt.SigningMethodHS256, "enc-1", f.claims([]any{"reader"}), []byte("encryption-key")), nil},
+		{"unsupported alg", sign(t, jwt.SigningMethodHS512, "hmac-1", f.claims([]any{"reader"}), f.secret), nil},
+		{"alg none", sign(t, jwt.SigningMethodNone, "", f.claims([]any{"admin"}), jwt.UnsafeAllowNoneSignatureType), nil},
+		{"expired", sign(t, jwt.SigningMethodHS256, "hmac-1", withClaim("exp", time.Now().Add(-time.Minute).Unix()), f.secret), nil},
+		{"without exp", sign(t, jwt.SigningMethodHS256, "hmac-1", withClaim("exp", nil), f.secret), nil},
+		{"wrong issuer", sign(t, jwt.SigningMethodHS256, "hmac-1", withClaim("iss", "https://evil.example.com"), f.secret), nil},
+		{"wrong audience", sign(t, jwt.SigningMethodHS256, "hmac-1", withClaim("aud", "other-service"), f.secret), nil},
+		{"no roles", sign(t, jwt.SigningMethodHS256, "hmac-1", withClaim("roles", nil), f.secret), nil},
+		{"unknown role", sign(t, jwt.SigningMethodHS256, "hmac-1", f.claims([]any{"owner"}), f.secret), nil},
+		{"malformed", "not.a.jwt", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := a.AuthenticateToken(tt.token)
+			if tt.wantRoles == nil {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("error = %v, want ErrInvalidToken", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("valid token rejected: %v", err)
+			}
+			if p.Method != "jwt" || p.Subject != "user-1" {
+				t.Errorf("principal = %+v, want jwt user-1", p)
+			}
+			if len(p.Roles) != len(tt.wantRoles) {
+				t.Fatalf("roles = %v, want %v", p.Roles, tt.wantRoles)
+			}
+			for i, role := range tt.wantRoles {
+				if p.Roles[i] != role {
+					t.Errorf("roles = %v, want %v", p.Roles, tt.wantRoles)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthenticateTokenRolesClaim(t *testing.T) {
+	f := newJWTFixture(t)
+	a, err := NewAuthenticator(Config{JWKSFile: f.file, RolesClaim: "scope"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := f.claims(nil)
+	claims["scope"] = "admin"
+	p, err := a.AuthenticateToken(sign(t, jwt.SigningMethodHS256, "hmac-1", claims, f.secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.HasRole(RoleAdmin) {
+		t.Errorf("roles = %v, want admin from the scope claim", p.Roles)
+	}
+}
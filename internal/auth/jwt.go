@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwk — поддерживаемое подмножество RFC 7517: симметричные (oct) и RSA-ключи.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	K   string `json:"k"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jwtVerifier struct {
+	hmacKeys   map[string][]byte
+	rsaKeys    map[string]*rsa.PublicKey
+	parser     *jwt.Parser
+	rolesClaim string
+}
+
+func newJWTVerifier(cfg Config) (*jwtVerifier, error) {
+	data, err := os.ReadFile(cfg.JWKSFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS file: %w", err)
+	}
+
+	v := &jwtVerifier{
+		hmacKeys:   make(map[string][]byte),
+		rsaKeys:    make(map[string]*rsa.PublicKey),
+		rolesClaim: cfg.RolesClaim,
+	}
+	if v.rolesClaim == "" {
+		v.rolesClaim = "roles"
+	}
+
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		switch key.Kty {
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(key.K, "="))
+			if err != nil {
+				return nil, fmt.Errorf("jwk %q: invalid k: %w", key.Kid, err)
+			}
+			v.hmacKeys[key.Kid] = secret
+		case "RSA":
+			pub, err := rsaPublicKey(key)
+			if err != nil {
+				return nil, fmt.Errorf("jwk %q: %w", key.Kid, err)
+			}
+			v.rsaKeys[key.Kid] = pub
+		}
+	}
+
+	if len(v.hmacKeys) == 0 && len(v.rsaKeys) == 0 {
+		return nil, errors.New("JWKS file contains no usable signing keys")
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	v.parser = jwt.NewParser(opts...)
+
+	return v, nil
+}
+
+func rsaPublicKey(key jwk) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(key.N, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid n: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(key.E, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid e: %w", err)
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
+
+// keyFunc выбирает ключ по kid; без kid подходит единственный ключ нужного типа.
+func (v *jwtVerifier) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return pickKey(v.hmacKeys, kid)
+	case jwt.SigningMethodRS256.Alg():
+		return pickKey(v.rsaKeys, kid)
+	default:
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+}
+
+func pickKey[K any](keys map[string]K, kid string) (any, error) {
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+func (v *jwtVerifier) verify(tokenString string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(tokenString, claims, v.keyFunc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	subject, _ := claims.GetSubject()
+	roles, err := parseRoles(claimStrings(claims[v.rolesClaim]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s claim: %v", ErrInvalidToken, v.rolesClaim, err)
+	}
+
+	return &Principal{Subject: subject, Roles: roles, Method: "jwt"}, nil
+}
+
+// claimStrings принимает claim ролей как массив строк или строку через пробел.
+func claimStrings(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
@@ -2,21 +2,121 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Port         string `env:"PORT" env-default:"8080"`
-	PostgresUser string `env:"POSTGRES_USER" env-required:"true"`
-	PostgresPass string `env:"POSTGRES_PASSWORD" env-required:"true"`
-	PostgresDB   string `env:"POSTGRES_DB" env-required:"true"`
-	PostgresHost string `env:"POSTGRES_HOST" env-required:"true"`
-	PostgresPort string `env:"POSTGRES_PORT" env-required:"true"`
-	KafkaBrokers string `env:"KAFKA_BROKERS" env-default:"localhost:9092"`
-	KafkaTopic   string `env:"KAFKA_TOPIC" env-default:"orders"`
-	KafkaGroupID string `env:"KAFKA_GROUP_ID" env-default:"order-viewer"`
+	Port          string   `env:"PORT" env-default:"8080"`
+	PostgresUser  string   `env:"POSTGRES_USER" env-required:"true"`
+	PostgresPass  string   `env:"POSTGRES_PASSWORD" env-required:"true"`
+	PostgresDB    string   `env:"POSTGRES_DB" env-required:"true"`
+	PostgresHost  string   `env:"POSTGRES_HOST" env-required:"true"`
+	PostgresPort  string   `env:"POSTGRES_PORT" env-required:"true"`
+	KafkaBrokers  string   `env:"KAFKA_BROKERS" env-default:"localhost:9092"`
+	KafkaTopics   []string `env:"KAFKA_TOPICS,KAFKA_TOPIC" env-default:"orders"`
+	KafkaGroupID  string   `env:"KAFKA_GROUP_ID" env-default:"order-viewer"`
+	KafkaDLQTopic string   `env:"KAFKA_DLQ_TOPIC"`
+
+	// Безопасное подключение к Kafka
+	KafkaSASLMechanism string        `env:"KAFKA_SASL_MECHANISM"` // plain, scram-sha-256, scram-sha-512
+	KafkaSASLUser      string        `env:"KAFKA_SASL_USER"`
+	KafkaSASLPassword  string        `env:"KAFKA_SASL_PASSWORD"`
+	KafkaTLSEnabled    bool          `env:"KAFKA_TLS_ENABLED" env-default:"false"`
+	KafkaTLSCAFile     string        `env:"KAFKA_TLS_CA_FILE"`
+	KafkaTLSCertFile   string        `env:"KAFKA_TLS_CERT_FILE"`
+	KafkaTLSKeyFile    string        `env:"KAFKA_TLS_KEY_FILE"`
+	KafkaTLSSkipVerify bool          `env:"KAFKA_TLS_INSECURE_SKIP_VERIFY" env-default:"false"`
+	KafkaDialTimeout   time.Duration `env:"KAFKA_DIAL_TIMEOUT" env-default:"10s"`
+
+	// Параметры чтения и смещений
+	KafkaMinBytes          int           `env:"KAFKA_MIN_BYTES" env-default:"1"`
+	KafkaMaxBytes          int           `env:"KAFKA_MAX_BYTES" env-default:"10485760"`
+	KafkaMaxWait           time.Duration `env:"KAFKA_MAX_WAIT" env-default:"10s"`
+	KafkaStartOffset       string        `env:"KAFKA_START_OFFSET" env-default:"first"` // first, last
+	KafkaCommitInterval    time.Duration `env:"KAFKA_COMMIT_INTERVAL" env-default:"0s"`
+	KafkaSessionTimeout    time.Duration `env:"KAFKA_SESSION_TIMEOUT" env-default:"30s"`
+	KafkaHeartbeatInterval time.Duration `env:"KAFKA_HEARTBEAT_INTERVAL" env-default:"3s"`
+	KafkaRebalanceTimeout  time.Duration `env:"KAFKA_REBALANCE_TIMEOUT" env-default:"30s"`
+	KafkaIsolationLevel    string        `env:"KAFKA_ISOLATION_LEVEL" env-default:"read_uncommitted"` // read_uncommitted, read_committed
+
+	// Формат сообщений без заголовка content-type и каталог с Avro-схемами
+	KafkaPayloadFormat string `env:"KAFKA_PAYLOAD_FORMAT" env-default:"json"` // json, protobuf, avro
+	KafkaAvroSchemaDir string `env:"KAFKA_AVRO_SCHEMA_DIR" env-default:"api/avro"`
+
+	// Параметры HTTP-сервера и разбора запросов
+	HTTPReadTimeout       time.Duration `env:"HTTP_READ_TIMEOUT" env-default:"15s"`
+	HTTPReadHeaderTimeout time.Duration `env:"HTTP_READ_HEADER_TIMEOUT" env-default:"5s"`
+	HTTPWriteTimeout      time.Duration `env:"HTTP_WRITE_TIMEOUT" env-default:"30s"`
+	HTTPIdleTimeout       time.Duration `env:"HTTP_IDLE_TIMEOUT" env-default:"120s"`
+	HTTPMaxBodyBytes      int64         `env:"HTTP_MAX_BODY_BYTES" env-default:"1048576"`
+	HTTPStrictDecoding    bool          `env:"HTTP_STRICT_DECODING" env-default:"false"`
+
+	// Сколько ждать завершения запросов HTTP и gRPC, консьюмера и outbox relay после SIGINT/SIGTERM
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" env-default:"15s"`
+
+	// Аутентификация: статические API-ключи (key:role[|role]) и JWT, проверяемые по локальному JWKS
+	AuthEnabled       bool     `env:"AUTH_ENABLED" env-default:"true"`
+	AuthAPIKeys       []string `env:"AUTH_API_KEYS"`
+	AuthJWKSFile      string   `env:"AUTH_JWKS_FILE"`
+	AuthJWTIssuer     string   `env:"AUTH_JWT_ISSUER"`
+	AuthJWTAudience   string   `env:"AUTH_JWT_AUDIENCE"`
+	AuthJWTRolesClaim string   `env:"AUTH_JWT_ROLES_CLAIM" env-default:"roles"`
+	AuthAnonymousRole string   `env:"AUTH_ANONYMOUS_ROLE"`
+
+	// Журнал: формат json или text, уровень debug, info, warn или error
+	LogFormat string `env:"LOG_FORMAT" env-default:"json"`
+	LogLevel  string `env:"LOG_LEVEL" env-default:"info"`
+
+	// Трассировка OpenTelemetry: экспортёр none, stdout, otlp-grpc или otlp-http;
+	// пустой TracingEndpoint — адрес коллектора по умолчанию (localhost:4317 или localhost:4318)
+	TracingExporter    string  `env:"TRACING_EXPORTER" env-default:"none"`
+	TracingEndpoint    string  `env:"TRACING_ENDPOINT"`
+	TracingInsecure    bool    `env:"TRACING_INSECURE" env-default:"true"`
+	TracingSampleRatio float64 `env:"TRACING_SAMPLE_RATIO" env-default:"1"`
+	TracingServiceName string  `env:"TRACING_SERVICE_NAME" env-default:"order-viewer"`
+
+	// Ограничения запросов GraphQL
+	GraphQLMaxDepth      int `env:"GRAPHQL_MAX_DEPTH" env-default:"6"`
+	GraphQLMaxComplexity int `env:"GRAPHQL_MAX_COMPLEXITY" env-default:"2000"`
+
+	// gRPC API на отдельном порту
+	GRPCEnabled bool   `env:"GRPC_ENABLED" env-default:"true"`
+	GRPCPort    string `env:"GRPC_PORT" env-default:"9090"`
+
+	// Ограничение частоты запросов на клиента в формате count/unit[:burst];
+	// RateLimitRoutes переопределяет лимит маршрута: "METHOD /pattern=count/unit[:burst]"
+	RateLimitEnabled bool     `env:"RATE_LIMIT_ENABLED" env-default:"true"`
+	RateLimitRead    string   `env:"RATE_LIMIT_READ" env-default:"50/s:100"`
+	RateLimitWrite   string   `env:"RATE_LIMIT_WRITE" env-default:"10/s:20"`
+	RateLimitMiss    string   `env:"RATE_LIMIT_MISS" env-default:"30/m:30"`
+	RateLimitRoutes  []string `env:"RATE_LIMIT_ROUTES" env-default:"POST /orders/import=6/m:2"`
+
+	// Маскирование персональных данных: роли с доступом к ним и стратегии off, full, partial, phone, email
+	PIIRoles       []string `env:"PII_ROLES" env-default:"admin"`
+	PIIMaskName    string   `env:"PII_MASK_NAME" env-default:"partial"`
+	PIIMaskPhone   string   `env:"PII_MASK_PHONE" env-default:"phone"`
+	PIIMaskAddress string   `env:"PII_MASK_ADDRESS" env-default:"full"`
+	PIIMaskEmail   string   `env:"PII_MASK_EMAIL" env-default:"email"`
+
+	// Лимиты пакетного импорта заказов
+	ImportMaxBodyBytes int64 `env:"IMPORT_MAX_BODY_BYTES" env-default:"67108864"`
+	ImportMaxLines     int   `env:"IMPORT_MAX_LINES" env-default:"10000"`
+
+	// Строгость правил согласованности заказа: error, warn или off
+	RulePaymentAmount      string `env:"RULE_PAYMENT_AMOUNT" env-default:"warn"`
+	RuleGoodsTotal         string `env:"RULE_GOODS_TOTAL" env-default:"error"`
+	RuleItemTotalPrice     string `env:"RULE_ITEM_TOTAL_PRICE" env-default:"warn"`
+	RuleItemTrackNumber    string `env:"RULE_ITEM_TRACK_NUMBER" env-default:"warn"`
+	RulePaymentTransaction string `env:"RULE_PAYMENT_TRANSACTION" env-default:"warn"`
+
+	// Публикация событий из outbox
+	OutboxEnabled      bool          `env:"OUTBOX_ENABLED" env-default:"true"`
+	OutboxTopic        string        `env:"OUTBOX_TOPIC" env-default:"order-events"`
+	OutboxPollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL" env-default:"1s"`
+	OutboxBatchSize    int           `env:"OUTBOX_BATCH_SIZE" env-default:"100"`
 }
 
 func NewConfig() (*Config, error) {
@@ -1,9 +1,62 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 var (
 	ErrOrderNotFound      = errors.New("order not found")
 	ErrOrderAlreadyExists = errors.New("order already exists")
 	ErrValidation         = errors.New("validation error")
 )
+
+// FieldError описывает нарушение правила валидации для поля, заданного JSON-путём.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func NewFieldError(field, rule, message string) *FieldError {
+	return &FieldError{Field: field, Rule: rule, Message: message}
+}
+
+func (e *FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// Is позволяет проверять FieldError через errors.Is(err, ErrValidation).
+func (e *FieldError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// ValidationErrors собирает все нарушения, найденные при валидации одного объекта.
+type ValidationErrors []*FieldError
+
+func (v *ValidationErrors) Add(field, rule, message string) {
+	*v = append(*v, NewFieldError(field, rule, message))
+}
+
+// Err возвращает nil, если нарушений нет, чтобы не получить ненулевой интерфейс error.
+func (v ValidationErrors) Err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+func (v ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(v))
+	for _, e := range v {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (v ValidationErrors) Is(target error) bool {
+	return target == ErrValidation
+}
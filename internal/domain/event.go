@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+const EventOrderCreated = "order.created"
+
+// OutboxEvent — событие, сохранённое в outbox в одной транзакции с заказом.
+type OutboxEvent struct {
+	ID          int64
+	AggregateID string
+	EventType   string
+	Payload     []byte
+	CreatedAt   time.Time
+	// TraceContext — контекст трассировки запроса, сохранившего заказ, в формате W3C
+	TraceContext map[string]string
+}
+
+// OrderCreatedEvent — полезная нагрузка события order.created.
+type OrderCreatedEvent struct {
+	EventType  string    `json:"event_type"`
+	OrderUID   string    `json:"order_uid"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Order      *Order    `json:"order"`
+}
+
+// OrderEvent — сохранённый заказ в ленте подписки. ID возрастает в пределах процесса
+// и используется для возобновления ленты после переподключения.
+type OrderEvent struct {
+	ID    string
+	Order *Order
+}
@@ -4,55 +4,59 @@ import (
 	"time"
 )
 
+// Правила валидации заданы тегами validate; пользовательские правила (nospace, phone,
+// currency, locale, notfuture) и перекрёстные проверки регистрируются в пакете service.
 type Order struct {
-	OrderUID          string    `json:"order_uid" db:"order_uid"`
-	TrackNumber       string    `json:"track_number" db:"track_number"`
+	OrderUID          string    `json:"order_uid" db:"order_uid" validate:"required,max=36,nospace"`
+	TrackNumber       string    `json:"track_number" db:"track_number" validate:"required,max=36"`
 	Entry             string    `json:"entry" db:"entry"`
 	Delivery          Delivery  `json:"delivery" db:"-"`
 	Payment           Payment   `json:"payment" db:"-"`
-	Items             []Item    `json:"items" db:"-"`
-	Locale            string    `json:"locale" db:"locale"`
+	Items             []Item    `json:"items" db:"-" validate:"min=1,dive"`
+	Locale            string    `json:"locale" db:"locale" validate:"omitempty,locale"`
 	InternalSignature string    `json:"internal_signature" db:"internal_signature"`
 	CustomerID        string    `json:"customer_id" db:"customer_id"`
 	DeliveryService   string    `json:"delivery_service" db:"delivery_service"`
 	Shardkey          string    `json:"shardkey" db:"shardkey"`
 	SmID              int       `json:"sm_id" db:"sm_id"`
-	DateCreated       time.Time `json:"date_created" db:"date_created"`
+	DateCreated       time.Time `json:"date_created" db:"date_created" validate:"required,notfuture"`
 	OofShard          string    `json:"oof_shard" db:"oof_shard"`
+	// Warnings — нарушения правил согласованности, с которыми заказ был принят
+	Warnings []*FieldError `json:"warnings,omitempty" db:"warnings"`
 }
 
 type Delivery struct {
-	Name    string `json:"name" db:"name"`
-	Phone   string `json:"phone" db:"phone"`
+	Name    string `json:"name" db:"name" validate:"required,max=100"`
+	Phone   string `json:"phone" db:"phone" validate:"required,phone"`
 	Zip     string `json:"zip" db:"zip"`
 	City    string `json:"city" db:"city"`
 	Address string `json:"address" db:"address"`
 	Region  string `json:"region" db:"region"`
-	Email   string `json:"email" db:"email"`
+	Email   string `json:"email" db:"email" validate:"required,max=254,email"`
 }
 
 type Payment struct {
-	Transaction  string `json:"transaction" db:"transaction"`
+	Transaction  string `json:"transaction" db:"transaction" validate:"required,max=36"`
 	RequestID    string `json:"request_id" db:"request_id"`
-	Currency     string `json:"currency" db:"currency"`
+	Currency     string `json:"currency" db:"currency" validate:"required,currency"`
 	Provider     string `json:"provider" db:"provider"`
-	Amount       int    `json:"amount" db:"amount"`
+	Amount       int    `json:"amount" db:"amount" validate:"gt=0"`
 	PaymentDt    int    `json:"payment_dt" db:"payment_dt"`
 	Bank         string `json:"bank" db:"bank"`
-	DeliveryCost int    `json:"delivery_cost" db:"delivery_cost"`
-	GoodsTotal   int    `json:"goods_total" db:"goods_total"`
-	CustomFee    int    `json:"custom_fee" db:"custom_fee"`
+	DeliveryCost int    `json:"delivery_cost" db:"delivery_cost" validate:"gte=0"`
+	GoodsTotal   int    `json:"goods_total" db:"goods_total" validate:"gte=0"`
+	CustomFee    int    `json:"custom_fee" db:"custom_fee" validate:"gte=0"`
 }
 
 type Item struct {
-	ChrtID      int    `json:"chrt_id" db:"chrt_id"`
+	ChrtID      int    `json:"chrt_id" db:"chrt_id" validate:"gt=0"`
 	TrackNumber string `json:"track_number" db:"track_number"`
-	Price       int    `json:"price" db:"price"`
+	Price       int    `json:"price" db:"price" validate:"gt=0,lte=100000000"`
 	RID         string `json:"rid" db:"rid"`
-	Name        string `json:"name" db:"name"`
-	Sale        int    `json:"sale" db:"sale"`
+	Name        string `json:"name" db:"name" validate:"required,max=200"`
+	Sale        int    `json:"sale" db:"sale" validate:"gte=0,lte=100"`
 	Size        string `json:"size" db:"size"`
-	TotalPrice  int    `json:"total_price" db:"total_price"`
+	TotalPrice  int    `json:"total_price" db:"total_price" validate:"gte=0"`
 	NmID        int    `json:"nm_id" db:"nm_id"`
 	Brand       string `json:"brand" db:"brand"`
 	Status      int    `json:"status" db:"status"`
@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+// OrderSort — порядок сортировки списка заказов.
+type OrderSort string
+
+const (
+	SortDateDesc     OrderSort = "-date_created"
+	SortDateAsc      OrderSort = "date_created"
+	SortOrderUIDAsc  OrderSort = "order_uid"
+	SortOrderUIDDesc OrderSort = "-order_uid"
+)
+
+// OrderFilter — параметры поиска заказов. Пустые поля не участвуют в фильтрации.
+type OrderFilter struct {
+	CustomerID      string
+	TrackNumber     string
+	DeliveryService string
+	Brand           string
+	From            time.Time
+	To              time.Time
+	Sort            OrderSort
+	Limit           int
+	After           *OrderCursor
+}
+
+// OrderCursor — позиция последнего заказа предыдущей страницы для keyset-пагинации.
+type OrderCursor struct {
+	Sort        OrderSort `json:"s"`
+	DateCreated time.Time `json:"d"`
+	OrderUID    string    `json:"u"`
+}
+
+// OrderSummary — краткое представление заказа для списков.
+type OrderSummary struct {
+	OrderUID        string    `json:"order_uid"`
+	TrackNumber     string    `json:"track_number"`
+	CustomerID      string    `json:"customer_id"`
+	DeliveryService string    `json:"delivery_service"`
+	DateCreated     time.Time `json:"date_created"`
+	Amount          int       `json:"amount"`
+	Currency        string    `json:"currency"`
+	ItemsCount      int       `json:"items_count"`
+}
+
+type OrderPage struct {
+	Orders     []OrderSummary `json:"orders"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,24 @@
+package domain
+
+// LookupStatus — результат поиска одного заказа в пакетном запросе.
+type LookupStatus string
+
+const (
+	LookupFound    LookupStatus = "found"
+	LookupNotFound LookupStatus = "not_found"
+	LookupInvalid  LookupStatus = "invalid"
+)
+
+// Источники данных заказа.
+const (
+	SourceCache    = "cache"
+	SourceDatabase = "database"
+)
+
+type OrderLookup struct {
+	OrderUID string       `json:"order_uid"`
+	Status   LookupStatus `json:"status"`
+	Source   string       `json:"source,omitempty"`
+	Order    *Order       `json:"order,omitempty"`
+	Error    string       `json:"error,omitempty"`
+}
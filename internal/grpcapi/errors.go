@@ -0,0 +1,58 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/platonso/order-viewer/internal/domain"
+	"github.com/platonso/order-viewer/internal/logging"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatus сопоставляет ошибку сервиса с gRPC-статусом. Ошибки валидации
+// передаются деталями google.rpc.BadRequest с путями полей.
+func toStatus(ctx context.Context, err error) error {
+	var (
+		validationErrs domain.ValidationErrors
+		fieldErr       *domain.FieldError
+	)
+
+	switch {
+	case errors.As(err, &validationErrs):
+		return validationStatus(validationErrs...)
+	case errors.As(err, &fieldErr):
+		return validationStatus(fieldErr)
+	case errors.Is(err, domain.ErrValidation):
+		return status.Error(codes.InvalidArgument, "request validation failed")
+	case errors.Is(err, domain.ErrOrderNotFound):
+		return status.Error(codes.NotFound, "order not found")
+	case errors.Is(err, domain.ErrOrderAlreadyExists):
+		return status.Error(codes.AlreadyExists, "order already exists")
+	default:
+		slog.ErrorContext(ctx, "grpc request failed", logging.Err(err))
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
+
+func validationStatus(errs ...*domain.FieldError) error {
+	st := status.New(codes.InvalidArgument, "request validation failed")
+
+	details := &errdetails.BadRequest{}
+	for _, e := range errs {
+		details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       e.Field,
+			Description: e.Message,
+			Reason:      e.Rule,
+		})
+	}
+
+	withDetails, err := st.WithDetails(details)
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
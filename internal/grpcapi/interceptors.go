@@ -0,0 +1,134 @@
+package grpcapi
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+
+	"github.com/platonso/order-viewer/internal/auth"
+	"github.com/platonso/order-viewer/internal/logging"
+	"github.com/platonso/order-viewer/internal/pb/orderpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDKey — ключ метаданных со сквозным идентификатором запроса.
+const requestIDKey = "x-request-id"
+
+// methodRoles — роли, необходимые для методов OrderService. Health и reflection доступны без аутентификации.
+var methodRoles = map[string]auth.Role{
+	orderpb.OrderService_GetOrder_FullMethodName:    auth.RoleReader,
+	orderpb.OrderService_ListOrders_FullMethodName:  auth.RoleReader,
+	orderpb.OrderService_WatchOrders_FullMethodName: auth.RoleReader,
+	orderpb.OrderService_CreateOrder_FullMethodName: auth.RoleWriter,
+}
+
+func authenticateUnary(authn *auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authorize(ctx, authn, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func authenticateStream(authn *auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), authn, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authorize определяет вызывающую сторону по метаданным x-api-key или authorization
+// и проверяет роль, необходимую для метода.
+func authorize(ctx context.Context, authn *auth.Authenticator, method string) (context.Context, error) {
+	role, ok := methodRoles[method]
+	if !ok {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var (
+		p   *auth.Principal
+		err error
+	)
+	if key := firstValue(md, "x-api-key"); key != "" {
+		p, err = authn.AuthenticateAPIKey(key)
+	} else if scheme, token, ok := strings.Cut(firstValue(md, "authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		p, err = authn.AuthenticateToken(strings.TrimSpace(token))
+	} else {
+		p, err = authn.Anonymous()
+	}
+	if err != nil {
+		return ctx, status.Error(codes.Unauthenticated, "valid API key or bearer token is required")
+	}
+
+	if !p.HasRole(role) {
+		return ctx, status.Errorf(codes.PermissionDenied, "role %s is required", role)
+	}
+	return auth.WithPrincipal(ctx, p), nil
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// contextStream подменяет контекст потока, чтобы обработчик видел вызывающую сторону.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// requestIDUnary и requestIDStream берут x-request-id из метаданных или генерируют новый
+// и возвращают его в заголовке ответа.
+func requestIDUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(withRequestID(ctx), req)
+}
+
+func requestIDStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &contextStream{ServerStream: ss, ctx: withRequestID(ss.Context())})
+}
+
+func withRequestID(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	id := firstValue(md, requestIDKey)
+	if !logging.ValidID(id) {
+		id = logging.NewID()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+	return logging.WithRequestID(ctx, id)
+}
+
+// recoverUnary и recoverStream превращают панику в обработчике в codes.Internal.
+func recoverUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer recoverPanic(ctx, info.FullMethod, &err)
+	return handler(ctx, req)
+}
+
+func recoverStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer recoverPanic(ss.Context(), info.FullMethod, &err)
+	return handler(srv, ss)
+}
+
+func recoverPanic(ctx context.Context, method string, err *error) {
+	if rec := recover(); rec != nil {
+		slog.ErrorContext(ctx, "panic in grpc handler", "method", method, "panic", rec, "stack", string(debug.Stack()))
+		*err = status.Error(codes.Internal, "internal server error")
+	}
+}
@@ -0,0 +1,43 @@
+package grpcapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/platonso/order-viewer/internal/metrics"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Метрики gRPC-вызовов с метками method и code.
+const (
+	metricGRPCRequests = "grpc_server_requests_total"
+	metricGRPCDuration = "grpc_server_request_duration_seconds"
+)
+
+// instrumentUnary и instrumentStream считают вызовы и их длительность по полному имени метода
+// и коду ответа. Стоят первыми в цепочке, чтобы учитывать и отказы аутентификации и лимитов.
+func instrumentUnary(recorder metrics.Recorder) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observeCall(recorder, info.FullMethod, err, time.Since(start))
+		return resp, err
+	}
+}
+
+func instrumentStream(recorder metrics.Recorder) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		observeCall(recorder, info.FullMethod, err, time.Since(start))
+		return err
+	}
+}
+
+func observeCall(recorder metrics.Recorder, method string, err error, d time.Duration) {
+	labels := []string{"method", method, "code", status.Code(err).String()}
+	recorder.Inc(metricGRPCRequests, labels...)
+	recorder.ObserveDuration(metricGRPCDuration, d, labels...)
+}
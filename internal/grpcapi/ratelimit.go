@@ -0,0 +1,113 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+
+	"github.com/platonso/order-viewer/internal/auth"
+	"github.com/platonso/order-viewer/internal/pb/orderpb"
+	"github.com/platonso/order-viewer/internal/ratelimit"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// RateLimits — бюджеты запросов на клиента. Это те же корзины, что у HTTP API,
+// поэтому смена протокола не даёт клиенту дополнительных запросов.
+// nil в Options.RateLimits отключает ограничение.
+type RateLimits struct {
+	Read  *ratelimit.Limiter
+	Write *ratelimit.Limiter
+	// Miss ограничивает запросы заказов, которых нет: они не попадают в кеш и идут в Postgres
+	Miss *ratelimit.Limiter
+}
+
+// class возвращает бюджет класса метода OrderService. Health и reflection не ограничиваются.
+func (l *RateLimits) class(method string) *ratelimit.Limiter {
+	switch method {
+	case orderpb.OrderService_GetOrder_FullMethodName,
+		orderpb.OrderService_ListOrders_FullMethodName,
+		orderpb.OrderService_WatchOrders_FullMethodName:
+		return l.Read
+	case orderpb.OrderService_CreateOrder_FullMethodName:
+		return l.Write
+	default:
+		return nil
+	}
+}
+
+// rateLimitUnary списывает токен из бюджета класса метода. Для GetOrder, как и в HTTP API,
+// клиент с исчерпанным бюджетом промахов отклоняется, а каждый NotFound списывает из него токен.
+func rateLimitUnary(limits *RateLimits) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if limits == nil {
+			return handler(ctx, req)
+		}
+		class := limits.class(info.FullMethod)
+		if class == nil {
+			return handler(ctx, req)
+		}
+
+		key := clientKey(ctx)
+		if res := class.Allow(key); !res.Allowed {
+			return nil, rateLimited(res)
+		}
+
+		if info.FullMethod != orderpb.OrderService_GetOrder_FullMethodName {
+			return handler(ctx, req)
+		}
+		if res := limits.Miss.Peek(key); !res.Allowed {
+			return nil, rateLimited(res)
+		}
+		resp, err := handler(ctx, req)
+		if status.Code(err) == codes.NotFound {
+			limits.Miss.Allow(key)
+		}
+		return resp, err
+	}
+}
+
+func rateLimitStream(limits *RateLimits) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if limits == nil {
+			return handler(srv, ss)
+		}
+		if class := limits.class(info.FullMethod); class != nil {
+			if res := class.Allow(clientKey(ss.Context())); !res.Allowed {
+				return rateLimited(res)
+			}
+		}
+		return handler(srv, ss)
+	}
+}
+
+// clientKey — ключ бюджета в том же формате, что у HTTP API: аутентифицированный субъект, иначе IP клиента.
+func clientKey(ctx context.Context) string {
+	if p := auth.FromContext(ctx); p != nil && (p.Method == "api_key" || p.Method == "jwt") {
+		return p.Method + ":" + p.Subject
+	}
+
+	pr, ok := peer.FromContext(ctx)
+	if !ok || pr.Addr == nil {
+		return "ip:unknown"
+	}
+	host, _, err := net.SplitHostPort(pr.Addr.String())
+	if err != nil {
+		host = pr.Addr.String()
+	}
+	return "ip:" + host
+}
+
+// rateLimited возвращает ResourceExhausted с паузой до следующего токена в деталях google.rpc.RetryInfo.
+func rateLimited(res ratelimit.Result) error {
+	st := status.New(codes.ResourceExhausted, "rate limit exceeded, retry later")
+	withDetails, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(res.RetryAfter)})
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
@@ -0,0 +1,193 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/platonso/order-viewer/internal/auth"
+	"github.com/platonso/order-viewer/internal/domain"
+	"github.com/platonso/order-viewer/internal/metrics"
+	"github.com/platonso/order-viewer/internal/pb/orderpb"
+	"github.com/platonso/order-viewer/internal/pii"
+	"github.com/platonso/order-viewer/internal/service"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// watchBuffer — сколько заказов ждёт отправки медленному подписчику WatchOrders.
+const watchBuffer = 64
+
+// Options задаёт маскирование персональных данных, ограничение частоты запросов и метрики, как в HTTP API.
+type Options struct {
+	PII pii.Policy
+	// PIIRoles — роли, которым персональные данные отдаются без маскирования
+	PIIRoles   []auth.Role
+	RateLimits *RateLimits
+	// Metrics — куда записываются метрики вызовов; nil отключает их
+	Metrics metrics.Recorder
+}
+
+type Server struct {
+	orderpb.UnimplementedOrderServiceServer
+
+	orderService *service.OrderService
+	opts         Options
+}
+
+// NewServer собирает gRPC-сервер с OrderService, health и reflection. Вызовы трассируются
+// через глобальный TracerProvider с продолжением трассировки из метаданных traceparent.
+// Выключенную аутентификацию задаёт auth.NewDisabledAuthenticator, opts.RateLimits == nil
+// отключает ограничение частоты запросов.
+func NewServer(orderService *service.OrderService, authn *auth.Authenticator, opts Options) *grpc.Server {
+	recorder := opts.Metrics
+	if recorder == nil {
+		recorder = metrics.Nop{}
+	}
+
+	srv := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(instrumentUnary(recorder), requestIDUnary, recoverUnary,
+			authenticateUnary(authn), rateLimitUnary(opts.RateLimits)),
+		grpc.ChainStreamInterceptor(instrumentStream(recorder), requestIDStream, recoverStream,
+			authenticateStream(authn), rateLimitStream(opts.RateLimits)),
+	)
+
+	orderpb.RegisterOrderServiceServer(srv, &Server{orderService: orderService, opts: opts})
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(orderpb.OrderService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, healthServer)
+
+	reflection.Register(srv)
+
+	return srv
+}
+
+func (s *Server) GetOrder(ctx context.Context, req *orderpb.GetOrderRequest) (*orderpb.GetOrderResponse, error) {
+	order, fromCache, err := s.orderService.GetOrder(ctx, req.GetOrderUid())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	source := domain.SourceDatabase
+	if fromCache {
+		source = domain.SourceCache
+	}
+
+	return &orderpb.GetOrderResponse{
+		Order:    orderpb.FromDomain(s.redact(ctx, order)),
+		Source:   source,
+		Warnings: warnings(order),
+	}, nil
+}
+
+func (s *Server) ListOrders(ctx context.Context, req *orderpb.ListOrdersRequest) (*orderpb.ListOrdersResponse, error) {
+	filter := domain.OrderFilter{
+		CustomerID:      req.GetCustomerId(),
+		TrackNumber:     req.GetTrackNumber(),
+		DeliveryService: req.GetDeliveryService(),
+		Brand:           req.GetBrand(),
+		Sort:            domain.OrderSort(req.GetSort()),
+		Limit:           int(req.GetLimit()),
+	}
+	if req.GetFrom() != nil {
+		filter.From = req.GetFrom().AsTime()
+	}
+	if req.GetTo() != nil {
+		filter.To = req.GetTo().AsTime()
+	}
+
+	page, err := s.orderService.ListOrders(ctx, filter, req.GetCursor())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	resp := &orderpb.ListOrdersResponse{
+		Orders:     make([]*orderpb.OrderSummary, 0, len(page.Orders)),
+		NextCursor: page.NextCursor,
+	}
+	for _, summary := range page.Orders {
+		resp.Orders = append(resp.Orders, orderpb.SummaryFromDomain(summary))
+	}
+	return resp, nil
+}
+
+func (s *Server) CreateOrder(ctx context.Context, req *orderpb.CreateOrderRequest) (*orderpb.CreateOrderResponse, error) {
+	if req.GetOrder() == nil {
+		return nil, toStatus(ctx, domain.NewFieldError("order", "required", "is required"))
+	}
+
+	order := orderpb.ToDomain(req.GetOrder())
+	if err := s.orderService.SaveOrder(ctx, order); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return &orderpb.CreateOrderResponse{
+		Order:    orderpb.FromDomain(s.redact(ctx, order)),
+		Warnings: warnings(order),
+	}, nil
+}
+
+// warnings возвращает нарушения правил согласованности, с которыми заказ был принят.
+func warnings(order *domain.Order) []*orderpb.FieldViolation {
+	var out []*orderpb.FieldViolation
+	for _, w := range order.Warnings {
+		out = append(out, &orderpb.FieldViolation{Field: w.Field, Rule: w.Rule, Message: w.Message})
+	}
+	return out
+}
+
+// WatchOrders отправляет заказы, сохранённые этим экземпляром сервиса после подписки.
+func (s *Server) WatchOrders(req *orderpb.WatchOrdersRequest, stream grpc.ServerStreamingServer[orderpb.Order]) error {
+	ctx := stream.Context()
+
+	sub := s.orderService.Subscribe("", watchBuffer)
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-sub.Events():
+			if !ok {
+				if errors.Is(sub.Err(), service.ErrServiceStopped) {
+					return status.Error(codes.Unavailable, "server is shutting down")
+				}
+				return status.Error(codes.ResourceExhausted, "client is too slow to receive orders")
+			}
+			if !matchesWatch(req, event.Order) {
+				continue
+			}
+			if err := stream.Send(orderpb.FromDomain(s.redact(ctx, event.Order))); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func matchesWatch(req *orderpb.WatchOrdersRequest, order *domain.Order) bool {
+	if req.GetCustomerId() != "" && req.GetCustomerId() != order.CustomerID {
+		return false
+	}
+	if req.GetDeliveryService() != "" && req.GetDeliveryService() != order.DeliveryService {
+		return false
+	}
+	return true
+}
+
+// redact маскирует персональные данные заказа, если у вызывающей стороны нет к ним доступа.
+func (s *Server) redact(ctx context.Context, order *domain.Order) *domain.Order {
+	principal := auth.FromContext(ctx)
+	for _, role := range s.opts.PIIRoles {
+		if principal.HasRole(role) {
+			return order
+		}
+	}
+	return s.opts.PII.Redact(order)
+}
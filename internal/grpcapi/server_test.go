@@ -0,0 +1,512 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/platonso/order-viewer/internal/auth"
+	"github.com/platonso/order-viewer/internal/domain"
+	"github.com/platonso/order-viewer/internal/metrics"
+	"github.com/platonso/order-viewer/internal/pb/orderpb"
+	"github.com/platonso/order-viewer/internal/pii"
+	"github.com/platonso/order-viewer/internal/ratelimit"
+	"github.com/platonso/order-viewer/internal/repository"
+	"github.com/platonso/order-viewer/internal/service"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Ключи API, с которыми собирается сервер в тестах.
+const (
+	testReaderKey = "test-reader-key"
+	testWriterKey = "test-writer-key"
+	testAdminKey  = "test-admin-key"
+)
+
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// memDB — DBRepository в памяти.
+type memDB struct {
+	mu     sync.Mutex
+	orders map[string]*domain.Order
+}
+
+func (db *memDB) Save(_ context.Context, order *domain.Order) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if _, ok := db.orders[order.OrderUID]; ok {
+		return domain.ErrOrderAlreadyExists
+	}
+	db.orders[order.OrderUID] = order
+	return nil
+}
+
+func (db *memDB) FindByID(_ context.Context, orderUID string) (*domain.Order, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	order, ok := db.orders[orderUID]
+	if !ok {
+		return nil, domain.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func (db *memDB) FindByIDs(ctx context.Context, orderUIDs []string) (map[string]*domain.Order, error) {
+	found := make(map[string]*domain.Order, len(orderUIDs))
+	for _, uid := range orderUIDs {
+		if order, err := db.FindByID(ctx, uid); err == nil {
+			found[uid] = order
+		}
+	}
+	return found, nil
+}
+
+func (db *memDB) List(context.Context, domain.OrderFilter) ([]domain.OrderSummary, error) {
+	return nil, nil
+}
+
+func (db *memDB) Close() {}
+
+func testOrder(uid string) *orderpb.Order {
+	return &orderpb.Order{
+		OrderUid:    uid,
+		TrackNumber: "WBILMTESTTRACK",
+		Entry:       "WBIL",
+		Locale:      "en",
+		CustomerId:  "test",
+		DateCreated: timestamppb.New(time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)),
+		Delivery: &orderpb.Delivery{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Email:   "test@gmail.com",
+		},
+		Payment: &orderpb.Payment{
+			Transaction:  uid,
+			Currency:     "USD",
+			Amount:       1817,
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+		},
+		Items: []*orderpb.Item{
+			{ChrtId: 9934930, TrackNumber: "WBILMTESTTRACK", Price: 453, Name: "Mascaras", Sale: 30, TotalPrice: 317},
+		},
+	}
+}
+
+type testServer struct {
+	conn         *grpc.ClientConn
+	client       orderpb.OrderServiceClient
+	orderService *service.OrderService
+	metrics      *metrics.Registry
+}
+
+// newTestServer поднимает сервер поверх bufconn. Персональные данные видит только admin,
+// расхождение goods_total с суммой товаров принимается с предупреждением.
+func newTestServer(t *testing.T, limits *RateLimits) *testServer {
+	t.Helper()
+
+	authn, err := auth.NewAuthenticator(auth.Config{APIKeys: []string{
+		testReaderKey + ":reader",
+		testWriterKey + ":writer",
+		testAdminKey + ":admin",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := &memDB{orders: make(map[string]*domain.Order)}
+	rules := service.ConsistencyRules{service.RuleGoodsTotal: service.SeverityWarn}
+	orderService := service.NewOrderService(db, repository.NewCacheRepo(), rules, metrics.Nop{})
+	registry := metrics.NewRegistry()
+	srv := NewServer(orderService, authn, Options{
+		PII:        pii.Policy{pii.FieldDeliveryName: pii.StrategyPartial, pii.FieldDeliveryPhone: pii.StrategyPhone},
+		PIIRoles:   []auth.Role{auth.RoleAdmin},
+		RateLimits: limits,
+		Metrics:    registry,
+	})
+
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return &testServer{conn: conn, client: orderpb.NewOrderServiceClient(conn), orderService: orderService, metrics: registry}
+}
+
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+}
+
+// create сохраняет заказ через CreateOrder с ключом writer.
+func (ts *testServer) create(t *testing.T, uid string) {
+	t.Helper()
+	if _, err := ts.client.CreateOrder(withKey(testWriterKey), &orderpb.CreateOrderRequest{Order: testOrder(uid)}); err != nil {
+		t.Fatalf("CreateOrder(%s): %v", uid, err)
+	}
+}
+
+func TestGetOrder(t *testing.T) {
+	ts := newTestServer(t, nil)
+	ts.create(t, "order-1")
+
+	resp, err := ts.client.GetOrder(withKey(testAdminKey), &orderpb.GetOrderRequest{OrderUid: "order-1"})
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if resp.GetOrder().GetOrderUid() != "order-1" {
+		t.Errorf("order_uid = %q, want order-1", resp.GetOrder().GetOrderUid())
+	}
+	if resp.GetSource() != domain.SourceCache {
+		t.Errorf("source = %q, want %q", resp.GetSource(), domain.SourceCache)
+	}
+
+	_, err = ts.client.GetOrder(withKey(testReaderKey), &orderpb.GetOrderRequest{OrderUid: "missing"})
+	if code := status.Code(err); code != codes.NotFound {
+		t.Errorf("GetOrder(missing) code = %s, want NotFound", code)
+	}
+}
+
+func TestOrderWarnings(t *testing.T) {
+	ts := newTestServer(t, nil)
+
+	order := testOrder("order-1")
+	order.Payment.GoodsTotal = 1000
+	created, err := ts.client.CreateOrder(withKey(testWriterKey), &orderpb.CreateOrderRequest{Order: order})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	ts.create(t, "order-2")
+
+	tests := []struct {
+		uid  string
+		want []string
+	}{
+		{"order-1", []string{"payment.goods_total/" + service.RuleGoodsTotal}},
+		{"order-2", nil},
+	}
+	for _, tt := range tests {
+		resp, err := ts.client.GetOrder(withKey(testReaderKey), &orderpb.GetOrderRequest{OrderUid: tt.uid})
+		if err != nil {
+			t.Fatalf("GetOrder(%s): %v", tt.uid, err)
+		}
+		var got []string
+		for _, w := range resp.GetWarnings() {
+			got = append(got, w.GetField()+"/"+w.GetRule())
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("GetOrder(%s) warnings = %v, want %v", tt.uid, got, tt.want)
+		}
+	}
+	if len(created.GetWarnings()) != 1 {
+		t.Errorf("CreateOrder warnings = %v, want 1", created.GetWarnings())
+	}
+}
+
+func TestCallMetrics(t *testing.T) {
+	ts := newTestServer(t, nil)
+	ts.create(t, "order-1")
+
+	_, _ = ts.client.GetOrder(withKey(testReaderKey), &orderpb.GetOrderRequest{OrderUid: "order-1"})
+	_, _ = ts.client.GetOrder(withKey(testReaderKey), &orderpb.GetOrderRequest{OrderUid: "missing"})
+	_, _ = ts.client.GetOrder(context.Background(), &orderpb.GetOrderRequest{OrderUid: "order-1"})
+
+	method := orderpb.OrderService_GetOrder_FullMethodName
+	for code, want := range map[codes.Code]int64{codes.OK: 1, codes.NotFound: 1, codes.Unauthenticated: 1} {
+		if got := ts.metrics.Counter(metricGRPCRequests, "method", method, "code", code.String()).Value(); got != want {
+			t.Errorf("%s %s: requests = %d, want %d", method, code, got, want)
+		}
+	}
+	if got := ts.metrics.Counter(metricGRPCRequests, "method", orderpb.OrderService_CreateOrder_FullMethodName, "code", codes.OK.String()).Value(); got != 1 {
+		t.Errorf("CreateOrder OK: requests = %d, want 1", got)
+	}
+	if got := ts.metrics.Histogram(metricGRPCDuration, metrics.DefaultLatencyBuckets, "method", method, "code", codes.OK.String()).Snapshot().Count; got != 1 {
+		t.Errorf("GetOrder OK: duration observations = %d, want 1", got)
+	}
+}
+
+func TestTracing(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+
+	ts := newTestServer(t, nil)
+	ts.create(t, "order-1")
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	ctx := metadata.AppendToOutgoingContext(withKey(testReaderKey), "traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	if _, err := ts.client.GetOrder(ctx, &orderpb.GetOrderRequest{OrderUid: "order-1"}); err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+
+	name := strings.TrimPrefix(orderpb.OrderService_GetOrder_FullMethodName, "/")
+	for _, span := range spans.Ended() {
+		if span.Name() != name {
+			continue
+		}
+		if span.SpanKind() != trace.SpanKindServer {
+			t.Errorf("span kind = %s, want server", span.SpanKind())
+		}
+		if got := span.SpanContext().TraceID().String(); got != traceID {
+			t.Errorf("trace id = %s, want %s from traceparent", got, traceID)
+		}
+		return
+	}
+	t.Fatalf("no server span %s", name)
+}
+
+func TestCreateOrderInvalidArgument(t *testing.T) {
+	ts := newTestServer(t, nil)
+
+	order := testOrder("order-1")
+	order.Delivery.Phone = "not a phone"
+	order.Payment.Currency = "XYZ"
+
+	_, err := ts.client.CreateOrder(withKey(testWriterKey), &orderpb.CreateOrderRequest{Order: order})
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("code = %s, want InvalidArgument: %v", st.Code(), err)
+	}
+
+	var violations []string
+	for _, detail := range st.Details() {
+		if br, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				violations = append(violations, v.GetField()+"/"+v.GetReason())
+			}
+		}
+	}
+	for _, want := range []string{"delivery.phone/phone", "payment.currency/currency"} {
+		if !slices.Contains(violations, want) {
+			t.Errorf("BadRequest details %v do not contain %s", violations, want)
+		}
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	ts := newTestServer(t, nil)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		call func(ctx context.Context) error
+		want codes.Code
+	}{
+		{"no credentials", context.Background(), func(ctx context.Context) error {
+			_, err := ts.client.GetOrder(ctx, &orderpb.GetOrderRequest{OrderUid: "order-1"})
+			return err
+		}, codes.Unauthenticated},
+		{"unknown key", withKey("wrong-key"), func(ctx context.Context) error {
+			_, err := ts.client.GetOrder(ctx, &orderpb.GetOrderRequest{OrderUid: "order-1"})
+			return err
+		}, codes.Unauthenticated},
+		{"reader creates order", withKey(testReaderKey), func(ctx context.Context) error {
+			_, err := ts.client.CreateOrder(ctx, &orderpb.CreateOrderRequest{Order: testOrder("order-1")})
+			return err
+		}, codes.PermissionDenied},
+		{"writer reads order", withKey(testWriterKey), func(ctx context.Context) error {
+			_, err := ts.client.GetOrder(ctx, &orderpb.GetOrderRequest{OrderUid: "order-1"})
+			return err
+		}, codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := status.Code(tt.call(tt.ctx)); code != tt.want {
+				t.Errorf("code = %s, want %s", code, tt.want)
+			}
+		})
+	}
+}
+
+func TestPIIMasking(t *testing.T) {
+	ts := newTestServer(t, nil)
+	ts.create(t, "order-1")
+
+	tests := []struct {
+		key       string
+		wantName  string
+		wantPhone string
+	}{
+		{testReaderKey, "T***", "+9***0000"},
+		{testAdminKey, "Test Testov", "+9720000000"},
+	}
+
+	for _, tt := range tests {
+		resp, err := ts.client.GetOrder(withKey(tt.key), &orderpb.GetOrderRequest{OrderUid: "order-1"})
+		if err != nil {
+			t.Fatalf("GetOrder with %s: %v", tt.key, err)
+		}
+		d := resp.GetOrder().GetDelivery()
+		if d.GetName() != tt.wantName || d.GetPhone() != tt.wantPhone {
+			t.Errorf("with %s: name, phone = %q, %q; want %q, %q", tt.key, d.GetName(), d.GetPhone(), tt.wantName, tt.wantPhone)
+		}
+	}
+}
+
+func TestWatchOrders(t *testing.T) {
+	ts := newTestServer(t, nil)
+
+	ctx, cancel := context.WithTimeout(withKey(testReaderKey), 5*time.Second)
+	defer cancel()
+
+	stream, err := ts.client.WatchOrders(ctx, &orderpb.WatchOrdersRequest{})
+	if err != nil {
+		t.Fatalf("WatchOrders: %v", err)
+	}
+
+	// Подписка создаётся на сервере асинхронно, поэтому заказы сохраняются, пока один из них не придёт
+	type result struct {
+		order *orderpb.Order
+		err   error
+	}
+	received := make(chan result, 1)
+	go func() {
+		order, err := stream.Recv()
+		received <- result{order, err}
+	}()
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for i := 0; ; i++ {
+		select {
+		case res := <-received:
+			if res.err != nil {
+				t.Fatalf("Recv: %v", res.err)
+			}
+			// Персональные данные маскируются и в потоке
+			if res.order.GetOrderUid() == "" || res.order.GetDelivery().GetName() != "T***" {
+				t.Errorf("unexpected order %q with name %q", res.order.GetOrderUid(), res.order.GetDelivery().GetName())
+			}
+			return
+		case <-ticker.C:
+			if err := ts.orderService.SaveOrder(context.Background(), orderpb.ToDomain(testOrder(fmt.Sprintf("watch-%d", i)))); err != nil {
+				t.Fatal(err)
+			}
+		case <-ctx.Done():
+			t.Fatal("no order received")
+		}
+	}
+}
+
+func TestHealth(t *testing.T) {
+	ts := newTestServer(t, nil)
+
+	resp, err := healthpb.NewHealthClient(ts.conn).Check(context.Background(),
+		&healthpb.HealthCheckRequest{Service: orderpb.OrderService_ServiceDesc.ServiceName})
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("status = %s, want SERVING", resp.GetStatus())
+	}
+}
+
+func TestReflection(t *testing.T) {
+	ts := newTestServer(t, nil)
+
+	stream, err := reflectionpb.NewServerReflectionClient(ts.conn).ServerReflectionInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = stream.CloseSend()
+
+	var services []string
+	for _, s := range resp.GetListServicesResponse().GetService() {
+		services = append(services, s.GetName())
+	}
+	if !slices.Contains(services, "order.v1.OrderService") {
+		t.Errorf("services = %v, want order.v1.OrderService", services)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	once := ratelimit.Rate{Count: 1, Per: time.Hour, Burst: 1}
+	ts := newTestServer(t, &RateLimits{
+		Read:  ratelimit.NewLimiter(ratelimit.Rate{Count: 10, Per: time.Hour, Burst: 10}),
+		Write: ratelimit.NewLimiter(once),
+		Miss:  ratelimit.NewLimiter(once),
+	})
+
+	ts.create(t, "order-1")
+	_, err := ts.client.CreateOrder(withKey(testWriterKey), &orderpb.CreateOrderRequest{Order: testOrder("order-2")})
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("second write code = %s, want ResourceExhausted", st.Code())
+	}
+	if len(st.Details()) == 0 {
+		t.Error("ResourceExhausted has no RetryInfo")
+	} else if _, ok := st.Details()[0].(*errdetails.RetryInfo); !ok {
+		t.Errorf("detail = %T, want RetryInfo", st.Details()[0])
+	}
+
+	// Бюджет промахов тратится только на NotFound; найденные заказы его не расходуют
+	ctx := withKey(testReaderKey)
+	for _, tt := range []struct {
+		uid  string
+		want codes.Code
+	}{
+		{"order-1", codes.OK},
+		{"missing", codes.NotFound},
+		{"order-1", codes.ResourceExhausted},
+	} {
+		_, err := ts.client.GetOrder(ctx, &orderpb.GetOrderRequest{OrderUid: tt.uid})
+		if code := status.Code(err); code != tt.want {
+			t.Errorf("GetOrder(%s) code = %s, want %s", tt.uid, code, tt.want)
+		}
+	}
+
+	// Health не ограничивается
+	if _, err := healthpb.NewHealthClient(ts.conn).Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Errorf("health check: %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package kafka
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/platonso/order-viewer/internal/domain"
+
+	"github.com/hamba/avro/v2"
+	"github.com/segmentio/kafka-go"
+)
+
+// avroAPI использует json-теги, чтобы domain.Order не требовал отдельных avro-тегов.
+var avroAPI = avro.Config{TagKey: "json"}.Freeze()
+
+// AvroRegistry — локальный реестр схем: файлы order-v<версия>.avsc в одном каталоге.
+type AvroRegistry struct {
+	schemas map[string]avro.Schema
+}
+
+func NewAvroRegistry(dir string) (*AvroRegistry, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "order-v*.avsc"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list avro schemas: %w", err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no avro schemas found in %s", dir)
+	}
+
+	registry := &AvroRegistry{schemas: make(map[string]avro.Schema)}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read avro schema %s: %w", path, err)
+		}
+
+		schema, err := avro.ParseWithCache(string(data), "", &avro.SchemaCache{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse avro schema %s: %w", path, err)
+		}
+
+		version := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "order-v"), ".avsc")
+		registry.schemas[version] = schema
+	}
+
+	return registry, nil
+}
+
+func (r *AvroRegistry) Schema(version string) (avro.Schema, error) {
+	schema, ok := r.schemas[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownSchemaVersion, version)
+	}
+	return schema, nil
+}
+
+// Marshal сериализует заказ по схеме указанной версии.
+func (r *AvroRegistry) Marshal(version string, order *domain.Order) ([]byte, error) {
+	schema, err := r.Schema(version)
+	if err != nil {
+		return nil, err
+	}
+	return avroAPI.Marshal(schema, order)
+}
+
+// AvroDecoder читает заказы в бинарном Avro; схема выбирается по заголовку schema-version.
+type AvroDecoder struct {
+	registry *AvroRegistry
+}
+
+func NewAvroDecoder(registry *AvroRegistry) *AvroDecoder {
+	return &AvroDecoder{registry: registry}
+}
+
+func (d *AvroDecoder) ContentType() string {
+	return ContentTypeAvro
+}
+
+func (d *AvroDecoder) Decode(msg kafka.Message) (*domain.Order, error) {
+	version := headerValue(msg.Headers, SchemaVersionHeader)
+	if version == "" {
+		version = defaultSchemaVersion
+	}
+
+	schema, err := d.registry.Schema(version)
+	if err != nil {
+		return nil, err
+	}
+
+	var order domain.Order
+	if err := avroAPI.Unmarshal(schema, msg.Value, &order); err != nil {
+		return nil, fmt.Errorf("%w: avro schema v%s: %v", ErrDecode, version, err)
+	}
+	return &order, nil
+}
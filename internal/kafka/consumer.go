@@ -3,65 +3,275 @@ package kafka
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/platonso/order-viewer/internal/config"
 	"github.com/platonso/order-viewer/internal/domain"
+	"github.com/platonso/order-viewer/internal/logging"
+	"github.com/platonso/order-viewer/internal/metrics"
 	"github.com/platonso/order-viewer/internal/service"
+	"github.com/platonso/order-viewer/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/segmentio/kafka-go"
 )
 
+// failureReason — причина, по которой сообщение не удалось обработать.
+type failureReason string
+
+const (
+	reasonDecode         failureReason = "decode"
+	reasonUnknownVersion failureReason = "unknown_version"
+	reasonValidation     failureReason = "validation"
+	reasonDuplicate      failureReason = "duplicate"
+	reasonDB             failureReason = "db"
+)
+
+// Заголовки, которые добавляются к сообщению при отправке в DLQ.
+const (
+	headerDLQReason    = "dlq-reason"
+	headerDLQError     = "dlq-error"
+	headerDLQTopic     = "dlq-source-topic"
+	headerDLQPartition = "dlq-source-partition"
+	headerDLQOffset    = "dlq-source-offset"
+	// headerDLQValidation содержит JSON-массив всех нарушений валидации
+	headerDLQValidation = "dlq-validation-errors"
+)
+
+// CorrelationIDHeader — заголовок со сквозным идентификатором, по которому заказ можно
+// проследить от продюсера до чтения через API.
+const CorrelationIDHeader = "correlation-id"
+
+type Consumer struct {
+	reader       *kafka.Reader
+	dlq          *kafka.Writer // nil, если DLQ не настроена
+	decoders     *decoderSet
+	orderService *service.OrderService
+	stats        *consumerStats
+	lag          *lagProbe
+	topics       []string
+	groupID      string
+}
+
 // StartConsumer запускает чтение сообщений из Kafka и сохраняет заказы через сервис.
-func StartConsumer(ctx context.Context, cfg *config.Config, orderService *service.OrderService) error {
-	brokers := strings.Split(cfg.KafkaBrokers, ",")
-	reader := kafka.NewReader(kafka.ReaderConfig{
-		Brokers: brokers,
-		GroupID: cfg.KafkaGroupID,
-		Topic:   cfg.KafkaTopic,
-	})
+// После отмены ctx консьюмер дообрабатывает текущее сообщение, закрывает reader и вызывает wg.Done.
+func StartConsumer(ctx context.Context, cfg *config.Config, orderService *service.OrderService, registry *metrics.Registry, wg *sync.WaitGroup) (*Consumer, error) {
+	readerConfig, err := newReaderConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kafka reader config: %w", err)
+	}
+
+	decoders, err := newDecoderSet(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kafka decoder config: %w", err)
+	}
+
+	topics := topicList(cfg.KafkaTopics)
+	c := &Consumer{
+		reader:       kafka.NewReader(readerConfig),
+		dlq:          newDLQWriter(cfg, readerConfig.Dialer),
+		decoders:     decoders,
+		orderService: orderService,
+		stats:        newConsumerStats(registry),
+		lag:          newLagProbe(cfg, readerConfig.Dialer, topics),
+		topics:       topics,
+		groupID:      cfg.KafkaGroupID,
+	}
 
+	go c.collectStats(ctx)
+
+	wg.Add(1)
 	go func() {
-		defer func() {
-			if err := reader.Close(); err != nil {
-				log.Printf("kafka reader close error: %v", err)
-			}
-		}()
+		defer wg.Done()
+		defer c.close()
 
-		log.Printf("Kafka consumer started. Brokers=%s Topic=%s Group=%s", cfg.KafkaBrokers, cfg.KafkaTopic, cfg.KafkaGroupID)
+		slog.Info("kafka consumer started",
+			"brokers", cfg.KafkaBrokers, "topics", strings.Join(c.topics, ","), "group", cfg.KafkaGroupID)
 
 		for {
 			select {
 			case <-ctx.Done():
-				log.Printf("Kafka consumer stopped: %v", ctx.Err())
+				slog.Info("kafka consumer stopped", logging.Err(ctx.Err()))
 				return
 			default:
-				msg, err := reader.ReadMessage(ctx)
+				msg, err := c.reader.ReadMessage(ctx)
 				if err != nil {
 					if ctx.Err() != nil {
-						log.Printf("Kafka consumer exiting: %v", ctx.Err())
+						slog.Info("kafka consumer exiting", logging.Err(ctx.Err()))
 						return
 					}
-					log.Printf("kafka read error: %v", err)
-					continue
-				}
-
-				var order domain.Order
-				if err := json.Unmarshal(msg.Value, &order); err != nil {
-					log.Printf("kafka invalid message, json error: %v", err)
+					slog.Error("kafka read error", logging.Err(err))
 					continue
 				}
 
-				ctxSave, cancel := context.WithTimeout(ctx, 5*time.Second)
-				if err := orderService.SaveOrder(ctxSave, &order); err != nil {
-					log.Printf("failed to save order from kafka: %v", err)
-				}
-				cancel()
+				// ReadMessage уже отметил смещение для коммита, поэтому начатое сообщение
+				// сохраняется до конца, даже если сервис останавливается
+				c.process(context.WithoutCancel(ctx), msg)
 			}
 		}
 	}()
 
-	return nil
+	return c, nil
+}
+
+// Status возвращает текущее состояние консьюмера: лаг, счётчики и задержки обработки.
+func (c *Consumer) Status() ConsumerStatus {
+	status := c.stats.status()
+	status.Topics = c.topics
+	status.GroupID = c.groupID
+	return status
+}
+
+func (c *Consumer) collectStats(ctx context.Context) {
+	ticker := time.NewTicker(statsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.stats.collectReaderStats(c.reader)
+			c.refreshLag(ctx)
+		}
+	}
+}
+
+// refreshLag обновляет лаг по партициям из смещений брокера.
+func (c *Consumer) refreshLag(ctx context.Context) {
+	fetchCtx, cancel := context.WithTimeout(ctx, statsInterval/2)
+	defer cancel()
+
+	offsets, err := c.lag.fetch(fetchCtx)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Warn("failed to refresh kafka partition lag", logging.Err(err))
+		}
+		return
+	}
+	c.stats.refreshLag(offsets, time.Now())
+}
+
+// process обрабатывает сообщение в спане, продолжающем трассировку продюсера из заголовков.
+func (c *Consumer) process(ctx context.Context, msg kafka.Message) {
+	ctx = logging.WithCorrelationID(ctx, correlationID(msg))
+	ctx = otel.GetTextMapPropagator().Extract(ctx, headerCarrier{&msg.Headers})
+	ctx, span := tracer.Start(ctx, "process "+msg.Topic,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(messageAttributes(msg)...),
+		trace.WithAttributes(
+			semconv.MessagingOperationTypeProcess,
+			semconv.MessagingConsumerGroupName(c.groupID),
+		),
+	)
+	defer span.End()
+
+	start := time.Now()
+	reason, err := c.handleMessage(ctx, msg)
+	c.stats.observe(msg, reason, time.Since(start))
+	if err != nil {
+		span.SetAttributes(attribute.String("kafka.failure_reason", string(reason)))
+		if reason != reasonDuplicate {
+			tracing.Fail(span, err)
+		}
+		c.handleFailure(ctx, msg, reason, err)
+	}
+}
+
+// handleMessage декодирует и сохраняет заказ; при ошибке возвращает её причину.
+func (c *Consumer) handleMessage(ctx context.Context, msg kafka.Message) (failureReason, error) {
+	order, err := c.decoders.Decode(msg)
+	if err != nil {
+		if errors.Is(err, ErrUnknownSchemaVersion) {
+			return reasonUnknownVersion, err
+		}
+		return reasonDecode, err
+	}
+
+	ctxSave, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := c.orderService.SaveOrder(ctxSave, order); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrValidation):
+			return reasonValidation, err
+		case errors.Is(err, domain.ErrOrderAlreadyExists):
+			return reasonDuplicate, err
+		default:
+			return reasonDB, err
+		}
+	}
+
+	return "", nil
+}
+
+// handleFailure логирует ошибку и перекладывает сообщение в DLQ, если она настроена.
+// Дубликаты в DLQ не отправляются: повторная доставка для Kafka — штатная ситуация.
+func (c *Consumer) handleFailure(ctx context.Context, msg kafka.Message, reason failureReason, err error) {
+	slog.WarnContext(ctx, "kafka message rejected",
+		"topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset, "reason", string(reason), logging.Err(err))
+
+	if c.dlq == nil || reason == reasonDuplicate {
+		return
+	}
+
+	headers := append([]kafka.Header{}, msg.Headers...)
+	// Сообщение в DLQ ссылается на спан неудачной обработки
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier{&headers})
+	headers = append(headers,
+		kafka.Header{Key: headerDLQReason, Value: []byte(reason)},
+		kafka.Header{Key: headerDLQError, Value: []byte(err.Error())},
+		kafka.Header{Key: headerDLQTopic, Value: []byte(msg.Topic)},
+		kafka.Header{Key: headerDLQPartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: headerDLQOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+	)
+
+	var validationErrs domain.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		if data, err := json.Marshal(validationErrs); err == nil {
+			headers = append(headers, kafka.Header{Key: headerDLQValidation, Value: data})
+		}
+	}
+
+	ctxWrite, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := c.dlq.WriteMessages(ctxWrite, kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}); err != nil {
+		slog.ErrorContext(ctx, "failed to write message to DLQ", logging.Err(err))
+	}
+}
+
+// correlationID берёт сквозной идентификатор из заголовков сообщения. Если продюсер его
+// не передал, идентификатором служат координаты сообщения в топике.
+func correlationID(msg kafka.Message) string {
+	for _, key := range []string{CorrelationIDHeader, "x-request-id"} {
+		if id := headerValue(msg.Headers, key); logging.ValidID(id) {
+			return id
+		}
+	}
+	return fmt.Sprintf("%s-%d-%d", msg.Topic, msg.Partition, msg.Offset)
+}
+
+func (c *Consumer) close() {
+	if err := c.reader.Close(); err != nil {
+		slog.Error("kafka reader close error", logging.Err(err))
+	}
+	if c.dlq != nil {
+		if err := c.dlq.Close(); err != nil {
+			slog.Error("kafka DLQ writer close error", logging.Err(err))
+		}
+	}
 }
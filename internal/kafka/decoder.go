@@ -0,0 +1,100 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"strings"
+
+	"github.com/platonso/order-viewer/internal/config"
+	"github.com/platonso/order-viewer/internal/domain"
+	"github.com/platonso/order-viewer/internal/logging"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// ContentTypeHeader — заголовок сообщения с форматом полезной нагрузки.
+const ContentTypeHeader = "content-type"
+
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeAvro     = "application/avro"
+)
+
+var ErrUnsupportedContentType = errors.New("unsupported content type")
+
+// Decoder декодирует сообщение Kafka определённого формата в domain.Order.
+type Decoder interface {
+	ContentType() string
+	Decode(msg kafka.Message) (*domain.Order, error)
+}
+
+// contentTypeAliases сводит распространённые варианты записи к каноническим типам.
+var contentTypeAliases = map[string]string{
+	"json":                               ContentTypeJSON,
+	"protobuf":                           ContentTypeProtobuf,
+	"application/protobuf":               ContentTypeProtobuf,
+	"application/vnd.google.protobuf":    ContentTypeProtobuf,
+	"avro":                               ContentTypeAvro,
+	"avro/binary":                        ContentTypeAvro,
+	"application/vnd.apache.avro+binary": ContentTypeAvro,
+}
+
+// decoderSet выбирает Decoder по заголовку content-type, а без него — по настройке.
+type decoderSet struct {
+	decoders map[string]Decoder
+	fallback Decoder
+}
+
+func newDecoderSet(cfg *config.Config) (*decoderSet, error) {
+	set := &decoderSet{decoders: make(map[string]Decoder)}
+	set.register(JSONDecoder{})
+	set.register(ProtobufDecoder{})
+
+	registry, err := NewAvroRegistry(cfg.KafkaAvroSchemaDir)
+	if err != nil {
+		slog.Warn("avro decoder disabled", logging.Err(err))
+	} else {
+		set.register(NewAvroDecoder(registry))
+	}
+
+	fallback, ok := set.decoders[normalizeContentType(cfg.KafkaPayloadFormat)]
+	if !ok {
+		return nil, fmt.Errorf("%w: default payload format %q", ErrUnsupportedContentType, cfg.KafkaPayloadFormat)
+	}
+	set.fallback = fallback
+
+	return set, nil
+}
+
+func (s *decoderSet) register(d Decoder) {
+	s.decoders[d.ContentType()] = d
+}
+
+func (s *decoderSet) Decode(msg kafka.Message) (*domain.Order, error) {
+	decoder := s.fallback
+
+	if ct := headerValue(msg.Headers, ContentTypeHeader); ct != "" {
+		d, ok := s.decoders[normalizeContentType(ct)]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnsupportedContentType, ct)
+		}
+		decoder = d
+	}
+
+	return decoder.Decode(msg)
+}
+
+func normalizeContentType(ct string) string {
+	if mediaType, _, err := mime.ParseMediaType(ct); err == nil {
+		ct = mediaType
+	}
+	ct = strings.ToLower(strings.TrimSpace(ct))
+
+	if canonical, ok := contentTypeAliases[ct]; ok {
+		return canonical
+	}
+	return ct
+}
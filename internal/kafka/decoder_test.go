@@ -0,0 +1,287 @@
+package kafka
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/platonso/order-viewer/internal/config"
+	"github.com/platonso/order-viewer/internal/domain"
+	"github.com/platonso/order-viewer/internal/pb/orderpb"
+
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
+)
+
+const testAvroSchemaDir = "../../api/avro"
+
+func testOrder() *domain.Order {
+	return &domain.Order{
+		OrderUID:        "b563feb7b2b84b6test",
+		TrackNumber:     "WBILMTESTTRACK",
+		Entry:           "WBIL",
+		Locale:          "en",
+		CustomerID:      "test",
+		DeliveryService: "meest",
+		Shardkey:        "9",
+		SmID:            99,
+		DateCreated:     time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		OofShard:        "1",
+		Delivery: domain.Delivery{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+		Payment: domain.Payment{
+			Transaction:  "b563feb7b2b84b6test",
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       1817,
+			PaymentDt:    1637907727,
+			Bank:         "alpha",
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+		},
+		Items: []domain.Item{{
+			ChrtID:      9934930,
+			TrackNumber: "WBILMTESTTRACK",
+			Price:       453,
+			RID:         "ab4219087a764ae0btest",
+			Name:        "Mascaras",
+			Sale:        30,
+			Size:        "0",
+			TotalPrice:  317,
+			NmID:        2389212,
+			Brand:       "Vivienne Sabo",
+			Status:      202,
+		}},
+	}
+}
+
+func message(value []byte, headers ...string) kafka.Message {
+	msg := kafka.Message{Topic: "orders", Value: value}
+	for i := 0; i+1 < len(headers); i += 2 {
+		msg.Headers = append(msg.Headers, kafka.Header{Key: headers[i], Value: []byte(headers[i+1])})
+	}
+	return msg
+}
+
+// assertOrder сравнивает поля, которые переносят все форматы.
+func assertOrder(t *testing.T, got, want *domain.Order) {
+	t.Helper()
+	if got.OrderUID != want.OrderUID || got.TrackNumber != want.TrackNumber || got.CustomerID != want.CustomerID {
+		t.Errorf("order = %+v, want %+v", got, want)
+	}
+	if !got.DateCreated.Equal(want.DateCreated) {
+		t.Errorf("date_created = %v, want %v", got.DateCreated, want.DateCreated)
+	}
+	if got.Delivery != want.Delivery {
+		t.Errorf("delivery = %+v, want %+v", got.Delivery, want.Delivery)
+	}
+	if got.Payment != want.Payment {
+		t.Errorf("payment = %+v, want %+v", got.Payment, want.Payment)
+	}
+	if len(got.Items) != len(want.Items) || got.Items[0] != want.Items[0] {
+		t.Errorf("items = %+v, want %+v", got.Items, want.Items)
+	}
+}
+
+func TestJSONDecoder(t *testing.T) {
+	order := testOrder()
+	v1, err := json.Marshal(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// v2 передаёт date_created как unix-время
+	var fields map[string]any
+	if err := json.Unmarshal(v1, &fields); err != nil {
+		t.Fatal(err)
+	}
+	fields["date_created"] = order.DateCreated.Unix()
+	v2, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	envelope := func(version string, payload []byte) []byte {
+		data, err := json.Marshal(map[string]any{"schema_version": json.RawMessage(version), "payload": json.RawMessage(payload)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	tests := []struct {
+		name    string
+		msg     kafka.Message
+		wantErr error
+	}{
+		{"v1 without version", message(v1), nil},
+		{"v1 header", message(v1, SchemaVersionHeader, "1"), nil},
+		{"v2 header", message(v2, SchemaVersionHeader, "2"), nil},
+		{"header is case-insensitive", message(v2, "Schema-Version", " 2 "), nil},
+		{"envelope string version", message(envelope(`"2"`, v2)), nil},
+		{"envelope numeric version", message(envelope(`1`, v1)), nil},
+		{"header overrides envelope", message(envelope(`"1"`, v2), SchemaVersionHeader, "2"), nil},
+		{"unknown header version", message(v1, SchemaVersionHeader, "3"), ErrUnknownSchemaVersion},
+		{"unknown envelope version", message(envelope(`"9"`, v1)), ErrUnknownSchemaVersion},
+		{"v2 payload as v1", message(v2, SchemaVersionHeader, "1"), ErrDecode},
+		{"malformed", message([]byte(`{"order_uid":`)), ErrDecode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := JSONDecoder{}.Decode(tt.msg)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertOrder(t, got, order)
+		})
+	}
+}
+
+func TestProtobufDecoder(t *testing.T) {
+	order := testOrder()
+	data, err := proto.Marshal(orderpb.FromDomain(order))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		msg     kafka.Message
+		wantErr error
+	}{
+		{"without version", message(data), nil},
+		{"v1 header", message(data, SchemaVersionHeader, "1"), nil},
+		{"future version", message(data, SchemaVersionHeader, "2"), ErrUnknownSchemaVersion},
+		{"unknown version", message(data, SchemaVersionHeader, "v1"), ErrUnknownSchemaVersion},
+		{"malformed", message([]byte{0xff, 0xff, 0xff}), ErrDecode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProtobufDecoder{}.Decode(tt.msg)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertOrder(t, got, order)
+		})
+	}
+}
+
+func TestAvroDecoder(t *testing.T) {
+	registry, err := NewAvroRegistry(testAvroSchemaDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := testOrder()
+	data, err := registry.Marshal("1", order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoder := NewAvroDecoder(registry)
+
+	tests := []struct {
+		name    string
+		msg     kafka.Message
+		wantErr error
+	}{
+		{"without version", message(data), nil},
+		{"v1 header", message(data, SchemaVersionHeader, "1"), nil},
+		{"unknown version", message(data, SchemaVersionHeader, "2"), ErrUnknownSchemaVersion},
+		{"truncated", message(data[:len(data)/2]), ErrDecode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decoder.Decode(tt.msg)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertOrder(t, got, order)
+		})
+	}
+}
+
+func TestDecoderSetRoutesByContentType(t *testing.T) {
+	set, err := newDecoderSet(&config.Config{KafkaPayloadFormat: "protobuf", KafkaAvroSchemaDir: testAvroSchemaDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order := testOrder()
+	jsonData, err := json.Marshal(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pbData, err := proto.Marshal(orderpb.FromDomain(order))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := NewAvroRegistry(testAvroSchemaDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	avroData, err := registry.Marshal("1", order)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		msg     kafka.Message
+		wantErr error
+	}{
+		{"json", message(jsonData, ContentTypeHeader, "application/json; charset=utf-8"), nil},
+		{"protobuf alias", message(pbData, ContentTypeHeader, "application/vnd.google.protobuf", SchemaVersionHeader, "1"), nil},
+		{"avro alias", message(avroData, ContentTypeHeader, "avro/binary", SchemaVersionHeader, "1"), nil},
+		{"fallback without content type", message(pbData), nil},
+		{"unsupported content type", message(jsonData, ContentTypeHeader, "text/xml"), ErrUnsupportedContentType},
+		{"version checked after routing", message(pbData, ContentTypeHeader, "protobuf", SchemaVersionHeader, "7"), ErrUnknownSchemaVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := set.Decode(tt.msg)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertOrder(t, got, order)
+		})
+	}
+
+	if _, err := newDecoderSet(&config.Config{KafkaPayloadFormat: "xml", KafkaAvroSchemaDir: testAvroSchemaDir}); !errors.Is(err, ErrUnsupportedContentType) {
+		t.Errorf("unknown default format: error = %v, want ErrUnsupportedContentType", err)
+	}
+}
@@ -0,0 +1,188 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/platonso/order-viewer/internal/config"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// newDialer собирает kafka.Dialer с учётом настроек TLS и SASL.
+func newDialer(cfg *config.Config) (*kafka.Dialer, error) {
+	dialer := &kafka.Dialer{
+		Timeout:   cfg.KafkaDialTimeout,
+		DualStack: true,
+	}
+
+	if cfg.KafkaTLSEnabled {
+		tlsConfig, err := newTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		dialer.TLS = tlsConfig
+	}
+
+	mechanism, err := newSASLMechanism(cfg)
+	if err != nil {
+		return nil, err
+	}
+	dialer.SASLMechanism = mechanism
+
+	return dialer, nil
+}
+
+func newTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.KafkaTLSSkipVerify,
+	}
+
+	if cfg.KafkaTLSCAFile != "" {
+		caPEM, err := os.ReadFile(cfg.KafkaTLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read kafka CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("kafka CA file contains no valid certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	// Клиентский сертификат нужен только для mTLS
+	if cfg.KafkaTLSCertFile != "" || cfg.KafkaTLSKeyFile != "" {
+		if cfg.KafkaTLSCertFile == "" || cfg.KafkaTLSKeyFile == "" {
+			return nil, errors.New("both kafka TLS cert and key files are required")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.KafkaTLSCertFile, cfg.KafkaTLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kafka client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func newSASLMechanism(cfg *config.Config) (sasl.Mechanism, error) {
+	switch strings.ToLower(cfg.KafkaSASLMechanism) {
+	case "":
+		return nil, nil
+	case "plain":
+		return plain.Mechanism{
+			Username: cfg.KafkaSASLUser,
+			Password: cfg.KafkaSASLPassword,
+		}, nil
+	case "scram-sha-256":
+		mechanism, err := scram.Mechanism(scram.SHA256, cfg.KafkaSASLUser, cfg.KafkaSASLPassword)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create scram-sha-256 mechanism: %w", err)
+		}
+		return mechanism, nil
+	case "scram-sha-512":
+		mechanism, err := scram.Mechanism(scram.SHA512, cfg.KafkaSASLUser, cfg.KafkaSASLPassword)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create scram-sha-512 mechanism: %w", err)
+		}
+		return mechanism, nil
+	default:
+		return nil, fmt.Errorf("unsupported kafka SASL mechanism: %q", cfg.KafkaSASLMechanism)
+	}
+}
+
+// newReaderConfig переносит параметры чтения и смещений из конфига в kafka.ReaderConfig.
+func newReaderConfig(cfg *config.Config) (kafka.ReaderConfig, error) {
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		return kafka.ReaderConfig{}, err
+	}
+
+	var startOffset int64
+	switch strings.ToLower(cfg.KafkaStartOffset) {
+	case "", "first", "earliest":
+		startOffset = kafka.FirstOffset
+	case "last", "latest":
+		startOffset = kafka.LastOffset
+	default:
+		return kafka.ReaderConfig{}, fmt.Errorf("unsupported kafka start offset: %q", cfg.KafkaStartOffset)
+	}
+
+	var isolationLevel kafka.IsolationLevel
+	switch strings.ToLower(cfg.KafkaIsolationLevel) {
+	case "", "read_uncommitted":
+		isolationLevel = kafka.ReadUncommitted
+	case "read_committed":
+		isolationLevel = kafka.ReadCommitted
+	default:
+		return kafka.ReaderConfig{}, fmt.Errorf("unsupported kafka isolation level: %q", cfg.KafkaIsolationLevel)
+	}
+
+	topics := topicList(cfg.KafkaTopics)
+	if len(topics) == 0 {
+		return kafka.ReaderConfig{}, errors.New("at least one kafka topic is required")
+	}
+
+	readerConfig := kafka.ReaderConfig{
+		Brokers:           strings.Split(cfg.KafkaBrokers, ","),
+		GroupID:           cfg.KafkaGroupID,
+		Dialer:            dialer,
+		MinBytes:          cfg.KafkaMinBytes,
+		MaxBytes:          cfg.KafkaMaxBytes,
+		MaxWait:           cfg.KafkaMaxWait,
+		StartOffset:       startOffset,
+		CommitInterval:    cfg.KafkaCommitInterval,
+		SessionTimeout:    cfg.KafkaSessionTimeout,
+		HeartbeatInterval: cfg.KafkaHeartbeatInterval,
+		RebalanceTimeout:  cfg.KafkaRebalanceTimeout,
+		IsolationLevel:    isolationLevel,
+	}
+
+	// Topic и GroupTopics взаимоисключающие; несколько топиков читаются только в группе
+	if len(topics) == 1 {
+		readerConfig.Topic = topics[0]
+	} else {
+		if cfg.KafkaGroupID == "" {
+			return kafka.ReaderConfig{}, errors.New("kafka group id is required to consume multiple topics")
+		}
+		readerConfig.GroupTopics = topics
+	}
+
+	return readerConfig, nil
+}
+
+// newDLQWriter создаёт writer для топика ошибочных сообщений или возвращает nil, если он не настроен.
+func newDLQWriter(cfg *config.Config, dialer *kafka.Dialer) *kafka.Writer {
+	if cfg.KafkaDLQTopic == "" {
+		return nil
+	}
+
+	return &kafka.Writer{
+		Addr:     kafka.TCP(strings.Split(cfg.KafkaBrokers, ",")...),
+		Topic:    cfg.KafkaDLQTopic,
+		Balancer: &kafka.Hash{},
+		Transport: &kafka.Transport{
+			TLS:  dialer.TLS,
+			SASL: dialer.SASLMechanism,
+		},
+	}
+}
+
+func topicList(topics []string) []string {
+	out := make([]string, 0, len(topics))
+	for _, t := range topics {
+		t = strings.TrimSpace(t)
+		if t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
@@ -0,0 +1,103 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/platonso/order-viewer/internal/config"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// lagProbe запрашивает у брокера концы партиций и смещения группы. Без него лаг партиции,
+// из которой перестали приходить сообщения, застывал бы на значении последнего сообщения.
+type lagProbe struct {
+	client  *kafka.Client
+	topics  []string
+	groupID string // пусто — позиция известна только по обработанным сообщениям
+}
+
+// partitionOffsets — смещения партиции по данным брокера.
+type partitionOffsets struct {
+	topic     string
+	partition int
+	// end — смещение следующего сообщения, которое будет записано в партицию
+	end int64
+	// committed — следующее смещение для чтения группой; -1, если группа ещё не коммитила
+	committed int64
+}
+
+func newLagProbe(cfg *config.Config, dialer *kafka.Dialer, topics []string) *lagProbe {
+	return &lagProbe{
+		client: &kafka.Client{
+			Addr:    kafka.TCP(strings.Split(cfg.KafkaBrokers, ",")...),
+			Timeout: statsInterval / 2,
+			Transport: &kafka.Transport{
+				TLS:  dialer.TLS,
+				SASL: dialer.SASLMechanism,
+			},
+		},
+		topics:  topics,
+		groupID: cfg.KafkaGroupID,
+	}
+}
+
+// fetch возвращает смещения всех партиций читаемых топиков.
+func (p *lagProbe) fetch(ctx context.Context) ([]partitionOffsets, error) {
+	meta, err := p.client.Metadata(ctx, &kafka.MetadataRequest{Topics: p.topics})
+	if err != nil {
+		return nil, err
+	}
+
+	requests := make(map[string][]kafka.OffsetRequest, len(meta.Topics))
+	partitions := make(map[string][]int, len(meta.Topics))
+	for _, topic := range meta.Topics {
+		if topic.Error != nil {
+			return nil, fmt.Errorf("topic %s: %w", topic.Name, topic.Error)
+		}
+		for _, partition := range topic.Partitions {
+			requests[topic.Name] = append(requests[topic.Name], kafka.LastOffsetOf(partition.ID))
+			partitions[topic.Name] = append(partitions[topic.Name], partition.ID)
+		}
+	}
+
+	ends, err := p.client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: requests})
+	if err != nil {
+		return nil, err
+	}
+
+	committed := make(map[string]map[int]int64, len(partitions))
+	if p.groupID != "" {
+		res, err := p.client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{GroupID: p.groupID, Topics: partitions})
+		if err != nil {
+			return nil, err
+		}
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		for topic, parts := range res.Topics {
+			committed[topic] = make(map[int]int64, len(parts))
+			for _, part := range parts {
+				if part.Error == nil {
+					committed[topic][part.Partition] = part.CommittedOffset
+				}
+			}
+		}
+	}
+
+	var offsets []partitionOffsets
+	for topic, parts := range ends.Topics {
+		for _, part := range parts {
+			if part.Error != nil {
+				continue
+			}
+			next, ok := committed[topic][part.Partition]
+			if !ok {
+				next = -1
+			}
+			offsets = append(offsets, partitionOffsets{topic: topic, partition: part.Partition, end: part.LastOffset, committed: next})
+		}
+	}
+	return offsets, nil
+}
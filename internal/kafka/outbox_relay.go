@@ -0,0 +1,175 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/platonso/order-viewer/internal/config"
+	"github.com/platonso/order-viewer/internal/domain"
+	"github.com/platonso/order-viewer/internal/logging"
+	"github.com/platonso/order-viewer/internal/repository"
+	"github.com/platonso/order-viewer/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Заголовки публикуемых событий.
+const (
+	headerEventID   = "event-id"
+	headerEventType = "event-type"
+)
+
+// OutboxRelay публикует события из outbox в Kafka. Событие помечается опубликованным
+// только после подтверждения записи брокером, поэтому доставка — at-least-once.
+type OutboxRelay struct {
+	repo      repository.OutboxRepository
+	writer    *kafka.Writer
+	interval  time.Duration
+	batchSize int
+}
+
+// StartOutboxRelay запускает фоновую публикацию событий из outbox.
+// После отмены ctx relay закрывает writer и вызывает wg.Done.
+func StartOutboxRelay(ctx context.Context, cfg *config.Config, repo repository.OutboxRepository, wg *sync.WaitGroup) error {
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		return fmt.Errorf("invalid kafka dialer config: %w", err)
+	}
+
+	relay := &OutboxRelay{
+		repo: repo,
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(strings.Split(cfg.KafkaBrokers, ",")...),
+			Topic:        cfg.OutboxTopic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			// Топик создаётся при первой публикации, если брокер это разрешает
+			AllowAutoTopicCreation: true,
+			Transport: &kafka.Transport{
+				TLS:  dialer.TLS,
+				SASL: dialer.SASLMechanism,
+			},
+		},
+		interval:  cfg.OutboxPollInterval,
+		batchSize: cfg.OutboxBatchSize,
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		relay.run(ctx)
+	}()
+
+	slog.Info("outbox relay started", "topic", cfg.OutboxTopic, "interval", cfg.OutboxPollInterval)
+	return nil
+}
+
+func (r *OutboxRelay) run(ctx context.Context) {
+	defer func() {
+		if err := r.writer.Close(); err != nil {
+			slog.Error("outbox writer close error", logging.Err(err))
+		}
+	}()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("outbox relay stopped", logging.Err(ctx.Err()))
+			return
+		case <-ticker.C:
+			// Разбираем outbox, пока есть полные пачки, чтобы не ждать следующего тика
+			for {
+				n, err := r.repo.ProcessOutbox(ctx, r.batchSize, r.publish)
+				if err != nil {
+					switch {
+					case ctx.Err() != nil:
+					case isUnknownTopic(err):
+						slog.Error("outbox topic does not exist and was not auto-created, create it on the broker",
+							"topic", r.writer.Topic, logging.Err(err))
+					default:
+						slog.Error("outbox relay error", logging.Err(err))
+					}
+					break
+				}
+				if n < r.batchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// isUnknownTopic сообщает, что брокер отклонил запись из-за отсутствующего топика.
+// WriteErrors содержит ошибки по сообщениям и не поддерживает errors.Is.
+func isUnknownTopic(err error) bool {
+	if errors.Is(err, kafka.UnknownTopicOrPartition) {
+		return true
+	}
+	var writeErrs kafka.WriteErrors
+	if errors.As(err, &writeErrs) {
+		for _, e := range writeErrs {
+			if errors.Is(e, kafka.UnknownTopicOrPartition) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *OutboxRelay) publish(ctx context.Context, events []domain.OutboxEvent) error {
+	topic := r.writer.Topic
+	messages := make([]kafka.Message, 0, len(events))
+	spans := make([]trace.Span, 0, len(events))
+	for _, event := range events {
+		msg := kafka.Message{
+			Key:   []byte(event.AggregateID),
+			Value: event.Payload,
+			Headers: []kafka.Header{
+				{Key: headerEventID, Value: []byte(strconv.FormatInt(event.ID, 10))},
+				{Key: headerEventType, Value: []byte(event.EventType)},
+				{Key: ContentTypeHeader, Value: []byte(ContentTypeJSON)},
+			},
+		}
+
+		// Спан отправки продолжает трассировку запроса, сохранившего заказ, и передаётся консьюмерам
+		parent := otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(event.TraceContext))
+		spanCtx, span := tracer.Start(parent, "send "+topic,
+			trace.WithSpanKind(trace.SpanKindProducer),
+			trace.WithAttributes(
+				semconv.MessagingSystemKafka,
+				semconv.MessagingDestinationName(topic),
+				semconv.MessagingKafkaMessageKey(event.AggregateID),
+				semconv.MessagingOperationTypeSend,
+			),
+		)
+		otel.GetTextMapPropagator().Inject(spanCtx, headerCarrier{&msg.Headers})
+
+		messages = append(messages, msg)
+		spans = append(spans, span)
+	}
+
+	err := r.writer.WriteMessages(ctx, messages...)
+	for _, span := range spans {
+		if err != nil {
+			tracing.Fail(span, err)
+		}
+		span.End()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to publish outbox events: %w", err)
+	}
+	return nil
+}
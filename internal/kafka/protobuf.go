@@ -0,0 +1,51 @@
+package kafka
+
+import (
+	"fmt"
+
+	"github.com/platonso/order-viewer/internal/domain"
+	"github.com/platonso/order-viewer/internal/pb/orderpb"
+
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
+)
+
+// protobufDecoders приводит сообщение версии схемы к domain.Order. Новая версия
+// добавляется сюда вместе с её сообщением в api/proto.
+var protobufDecoders = map[string]orderDecoder{
+	"1": decodeProtobufV1,
+}
+
+// ProtobufDecoder читает заказы, сериализованные как orderpb.Order; версия схемы берётся из заголовка.
+type ProtobufDecoder struct{}
+
+func (ProtobufDecoder) ContentType() string {
+	return ContentTypeProtobuf
+}
+
+func (ProtobufDecoder) Decode(msg kafka.Message) (*domain.Order, error) {
+	version := headerValue(msg.Headers, SchemaVersionHeader)
+	if version == "" {
+		version = defaultSchemaVersion
+	}
+
+	decode, ok := protobufDecoders[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownSchemaVersion, version)
+	}
+
+	order, err := decode(msg.Value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: protobuf schema v%s: %v", ErrDecode, version, err)
+	}
+	return order, nil
+}
+
+// decodeProtobufV1 — orderpb.Order из api/proto/order.proto.
+func decodeProtobufV1(payload []byte) (*domain.Order, error) {
+	var pb orderpb.Order
+	if err := proto.Unmarshal(payload, &pb); err != nil {
+		return nil, err
+	}
+	return orderpb.ToDomain(&pb), nil
+}
@@ -0,0 +1,111 @@
+package kafka
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/platonso/order-viewer/internal/domain"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// SchemaVersionHeader — заголовок сообщения с версией схемы заказа.
+const SchemaVersionHeader = "schema-version"
+
+// defaultSchemaVersion используется для сообщений без заголовка и конверта.
+const defaultSchemaVersion = "1"
+
+var (
+	ErrUnknownSchemaVersion = errors.New("unknown schema version")
+	ErrDecode               = errors.New("failed to decode message")
+)
+
+// orderDecoder приводит полезную нагрузку конкретной версии к domain.Order.
+type orderDecoder func(payload []byte) (*domain.Order, error)
+
+var orderDecoders = map[string]orderDecoder{
+	"1": decodeOrderV1,
+	"2": decodeOrderV2,
+}
+
+// envelope — необязательная обёртка, в которой версия передаётся рядом с заказом.
+type envelope struct {
+	SchemaVersion json.RawMessage `json:"schema_version"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// JSONDecoder читает заказы в JSON; версия схемы берётся из заголовка или конверта.
+type JSONDecoder struct{}
+
+func (JSONDecoder) ContentType() string {
+	return ContentTypeJSON
+}
+
+func (JSONDecoder) Decode(msg kafka.Message) (*domain.Order, error) {
+	version := headerValue(msg.Headers, SchemaVersionHeader)
+	payload := msg.Value
+
+	var env envelope
+	if err := json.Unmarshal(msg.Value, &env); err == nil && len(env.Payload) > 0 {
+		payload = env.Payload
+		if version == "" {
+			version = strings.Trim(string(env.SchemaVersion), `"`)
+		}
+	}
+
+	if version == "" {
+		version = defaultSchemaVersion
+	}
+
+	decode, ok := orderDecoders[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownSchemaVersion, version)
+	}
+
+	order, err := decode(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: schema v%s: %v", ErrDecode, version, err)
+	}
+
+	return order, nil
+}
+
+// decodeOrderV1 — исходный формат: domain.Order в JSON как есть.
+func decodeOrderV1(payload []byte) (*domain.Order, error) {
+	var order domain.Order
+	if err := json.Unmarshal(payload, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// orderV2 отличается от v1 тем, что date_created передаётся как unix-время в секундах.
+type orderV2 struct {
+	domain.Order
+	DateCreated int64 `json:"date_created"`
+}
+
+func decodeOrderV2(payload []byte) (*domain.Order, error) {
+	var v2 orderV2
+	if err := json.Unmarshal(payload, &v2); err != nil {
+		return nil, err
+	}
+
+	order := v2.Order
+	if v2.DateCreated != 0 {
+		order.DateCreated = time.Unix(v2.DateCreated, 0).UTC()
+	}
+	return &order, nil
+}
+
+func headerValue(headers []kafka.Header, key string) string {
+	for _, h := range headers {
+		if strings.EqualFold(h.Key, key) {
+			return strings.TrimSpace(string(h.Value))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,192 @@
+package kafka
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/platonso/order-viewer/internal/metrics"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// statsInterval — период, с которым снимается статистика reader.Stats().
+const statsInterval = 10 * time.Second
+
+// Имена метрик консьюмера в реестре.
+const (
+	metricProcessed     = "kafka_consumer_messages_processed_total"
+	metricFailed        = "kafka_consumer_messages_failed_total"
+	metricLatency       = "kafka_consumer_processing_seconds"
+	metricLastProcessed = "kafka_consumer_last_processed_timestamp_seconds"
+	metricPartitionLag  = "kafka_consumer_partition_lag"
+	metricReaderLag     = "kafka_consumer_reader_lag"
+	metricReaderErrors  = "kafka_consumer_reader_errors_total"
+)
+
+// ConsumerStatus — состояние консьюмера для административного эндпоинта.
+type ConsumerStatus struct {
+	Topics          []string                  `json:"topics"`
+	GroupID         string                    `json:"group_id"`
+	Processed       int64                     `json:"processed"`
+	Failed          map[string]int64          `json:"failed"`
+	ReaderLag       int64                     `json:"reader_lag"`
+	ReaderErrors    int64                     `json:"reader_errors"`
+	Partitions      []PartitionStatus         `json:"partitions"`
+	Latency         metrics.HistogramSnapshot `json:"processing_latency"`
+	LastProcessedAt *time.Time                `json:"last_processed_at,omitempty"`
+}
+
+type PartitionStatus struct {
+	Topic     string `json:"topic"`
+	Partition int    `json:"partition"`
+	// Offset — смещение последнего прочитанного сообщения
+	Offset        int64     `json:"offset"`
+	HighWaterMark int64     `json:"high_water_mark"`
+	Lag           int64     `json:"lag"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+var failureReasons = []failureReason{
+	reasonDecode,
+	reasonUnknownVersion,
+	reasonValidation,
+	reasonDuplicate,
+	reasonDB,
+}
+
+// consumerStats записывает метрики обработки в общий реестр и хранит лаг по партициям.
+type consumerStats struct {
+	registry *metrics.Registry
+
+	processed     *metrics.Counter
+	failed        map[failureReason]*metrics.Counter
+	latency       *metrics.Histogram
+	lastProcessed *metrics.Gauge
+	readerLag     *metrics.Gauge
+	readerErrors  *metrics.Counter
+
+	mu         sync.RWMutex
+	partitions map[string]*PartitionStatus
+}
+
+func newConsumerStats(registry *metrics.Registry) *consumerStats {
+	s := &consumerStats{
+		registry:      registry,
+		processed:     registry.Counter(metricProcessed),
+		failed:        make(map[failureReason]*metrics.Counter, len(failureReasons)),
+		latency:       registry.Histogram(metricLatency, metrics.DefaultLatencyBuckets),
+		lastProcessed: registry.Gauge(metricLastProcessed),
+		readerLag:     registry.Gauge(metricReaderLag),
+		readerErrors:  registry.Counter(metricReaderErrors),
+		partitions:    make(map[string]*PartitionStatus),
+	}
+	for _, reason := range failureReasons {
+		s.failed[reason] = registry.Counter(metricFailed, "reason", string(reason))
+	}
+	return s
+}
+
+// observe учитывает результат обработки одного сообщения.
+func (s *consumerStats) observe(msg kafka.Message, reason failureReason, duration time.Duration) {
+	now := time.Now()
+
+	s.latency.Observe(duration.Seconds())
+	s.lastProcessed.Set(float64(now.Unix()))
+	if reason == "" {
+		s.processed.Inc()
+	} else {
+		s.failed[reason].Inc()
+	}
+
+	// HighWaterMark — смещение следующего сообщения, которое будет записано в партицию
+	s.mu.Lock()
+	s.setPartition(msg.Topic, msg.Partition, msg.Offset+1, msg.HighWaterMark, now)
+	s.mu.Unlock()
+}
+
+// refreshLag пересчитывает лаг всех партиций по смещениям брокера, в том числе партиций,
+// из которых давно не приходили сообщения. Позиция консьюмера — большее из смещения,
+// закоммиченного группой, и следующего за последним обработанным сообщением.
+func (s *consumerStats) refreshLag(offsets []partitionOffsets, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, o := range offsets {
+		next := o.committed
+		if p, ok := s.partitions[partitionKey(o.topic, o.partition)]; ok {
+			next = max(next, p.Offset+1)
+		}
+		// Группа ещё ничего не прочитала из партиции
+		if next < 0 {
+			continue
+		}
+		s.setPartition(o.topic, o.partition, next, o.end, now)
+	}
+}
+
+// setPartition записывает лаг партиции в реестр и состояние; вызывается под s.mu.
+func (s *consumerStats) setPartition(topic string, partition int, next, end int64, now time.Time) {
+	lag := partitionLag(end, next)
+	s.registry.Gauge(metricPartitionLag, "topic", topic, "partition", strconv.Itoa(partition)).Set(float64(lag))
+	s.partitions[partitionKey(topic, partition)] = &PartitionStatus{
+		Topic:         topic,
+		Partition:     partition,
+		Offset:        next - 1,
+		HighWaterMark: end,
+		Lag:           lag,
+		UpdatedAt:     now,
+	}
+}
+
+// partitionLag — сколько сообщений партиции консьюмер ещё не прочитал: end — смещение
+// следующего записываемого сообщения, next — следующего читаемого.
+func partitionLag(end, next int64) int64 {
+	return max(0, end-next)
+}
+
+func partitionKey(topic string, partition int) string {
+	return topic + "/" + strconv.Itoa(partition)
+}
+
+// collectReaderStats переносит reader.Stats() в реестр. Stats() сбрасывает счётчики при каждом вызове,
+// поэтому его читает только этот метод.
+func (s *consumerStats) collectReaderStats(reader *kafka.Reader) {
+	stats := reader.Stats()
+	s.readerLag.Set(float64(stats.Lag))
+	s.readerErrors.Add(stats.Errors)
+}
+
+func (s *consumerStats) status() ConsumerStatus {
+	status := ConsumerStatus{
+		Processed:    s.processed.Value(),
+		Failed:       make(map[string]int64, len(s.failed)),
+		ReaderLag:    int64(s.readerLag.Value()),
+		ReaderErrors: s.readerErrors.Value(),
+		Latency:      s.latency.Snapshot(),
+	}
+	for reason, c := range s.failed {
+		status.Failed[string(reason)] = c.Value()
+	}
+	if ts := s.lastProcessed.Value(); ts > 0 {
+		t := time.Unix(int64(ts), 0).UTC()
+		status.LastProcessedAt = &t
+	}
+
+	s.mu.RLock()
+	for _, p := range s.partitions {
+		status.Partitions = append(status.Partitions, *p)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(status.Partitions, func(i, j int) bool {
+		a, b := status.Partitions[i], status.Partitions[j]
+		if a.Topic != b.Topic {
+			return a.Topic < b.Topic
+		}
+		return a.Partition < b.Partition
+	})
+
+	return status
+}
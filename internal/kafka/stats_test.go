@@ -0,0 +1,71 @@
+package kafka
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/platonso/order-viewer/internal/metrics"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestPartitionLag(t *testing.T) {
+	tests := []struct {
+		end, next int64
+		want      int64
+	}{
+		{end: 10, next: 10, want: 0},
+		{end: 10, next: 4, want: 6},
+		{end: 0, next: 0, want: 0},
+		// Закоммиченное смещение может опередить устаревший конец партиции
+		{end: 10, next: 12, want: 0},
+	}
+	for _, tt := range tests {
+		if got := partitionLag(tt.end, tt.next); got != tt.want {
+			t.Errorf("partitionLag(%d, %d) = %d, want %d", tt.end, tt.next, got, tt.want)
+		}
+	}
+}
+
+func TestRefreshLag(t *testing.T) {
+	registry := metrics.NewRegistry()
+	stats := newConsumerStats(registry)
+	gauge := func(partition int) float64 {
+		return registry.Gauge(metricPartitionLag, "topic", "orders", "partition", strconv.Itoa(partition)).Value()
+	}
+
+	// Сообщение 9 было последним в партиции 0
+	stats.observe(kafka.Message{Topic: "orders", Partition: 0, Offset: 9, HighWaterMark: 10}, "", time.Millisecond)
+	if got := gauge(0); got != 0 {
+		t.Fatalf("lag after the last message = %v, want 0", got)
+	}
+
+	stats.refreshLag([]partitionOffsets{
+		// Партиция 0 перестала отдавать сообщения, а продюсер записал ещё 40;
+		// коммит группы отстаёт от обработанного сообщения
+		{topic: "orders", partition: 0, end: 50, committed: 3},
+		// Из партиции 1 этот процесс ничего не читал, позиция известна только по коммиту группы
+		{topic: "orders", partition: 1, end: 7, committed: 5},
+		// Группа ещё не коммитила партицию 2
+		{topic: "orders", partition: 2, end: 100, committed: -1},
+	}, time.Now())
+
+	if got := gauge(0); got != 40 {
+		t.Errorf("stalled partition lag = %v, want 40", got)
+	}
+	if got := gauge(1); got != 2 {
+		t.Errorf("committed-only partition lag = %v, want 2", got)
+	}
+
+	status := stats.status()
+	if len(status.Partitions) != 2 {
+		t.Fatalf("partitions = %+v, want 0 and 1", status.Partitions)
+	}
+	if p := status.Partitions[0]; p.Partition != 0 || p.Offset != 9 || p.HighWaterMark != 50 || p.Lag != 40 {
+		t.Errorf("partition 0 status = %+v", p)
+	}
+	if p := status.Partitions[1]; p.Partition != 1 || p.Offset != 4 || p.Lag != 2 {
+		t.Errorf("partition 1 status = %+v", p)
+	}
+}
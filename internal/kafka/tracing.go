@@ -0,0 +1,56 @@
+package kafka
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/platonso/order-viewer/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+
+	"github.com/segmentio/kafka-go"
+)
+
+var tracer = tracing.Tracer("internal/kafka")
+
+// headerCarrier передаёт контекст трассировки через заголовки сообщения Kafka.
+type headerCarrier struct {
+	headers *[]kafka.Header
+}
+
+var _ propagation.TextMapCarrier = headerCarrier{}
+
+func (c headerCarrier) Get(key string) string {
+	return headerValue(*c.headers, key)
+}
+
+// Set заменяет заголовок key: при копировании заголовков в DLQ старый контекст не должен остаться.
+func (c headerCarrier) Set(key, value string) {
+	headers := (*c.headers)[:0:0]
+	for _, h := range *c.headers {
+		if !strings.EqualFold(h.Key, key) {
+			headers = append(headers, h)
+		}
+	}
+	*c.headers = append(headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c.headers))
+	for _, h := range *c.headers {
+		keys = append(keys, h.Key)
+	}
+	return keys
+}
+
+// messageAttributes — атрибуты спана для сообщения в топике.
+func messageAttributes(msg kafka.Message) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.MessagingSystemKafka,
+		semconv.MessagingDestinationName(msg.Topic),
+		semconv.MessagingDestinationPartitionID(strconv.Itoa(msg.Partition)),
+		semconv.MessagingKafkaOffset(int(msg.Offset)),
+		semconv.MessagingKafkaMessageKey(string(msg.Key)),
+	}
+}
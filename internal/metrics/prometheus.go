@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ContentTypePrometheus — текстовый формат экспозиции Prometheus.
+const ContentTypePrometheus = "text/plain; version=0.0.4; charset=utf-8"
+
+// Handler отдаёт метрики реестра и рантайма Go в текстовом формате Prometheus.
+func Handler(r *Registry) http.Handler {
+	runtime := newRuntimeCollector(r)
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		runtime.collect()
+		w.Header().Set("Content-Type", ContentTypePrometheus)
+		_ = r.WritePrometheus(w)
+	})
+}
+
+// WritePrometheus записывает текущие значения метрик в текстовом формате Prometheus.
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	s := r.Snapshot()
+	bw := bufio.NewWriter(w)
+
+	writeSamples(bw, "counter", s.Counters)
+	writeSamples(bw, "gauge", s.Gauges)
+
+	// Серии одной метрики должны идти подряд под одной строкой TYPE
+	sort.SliceStable(s.Histograms, func(i, j int) bool { return s.Histograms[i].Name < s.Histograms[j].Name })
+	for i, h := range s.Histograms {
+		if i == 0 || s.Histograms[i-1].Name != h.Name {
+			writeType(bw, h.Name, "histogram")
+		}
+		for _, b := range h.Buckets {
+			writeSeries(bw, h.Name+"_bucket", h.Labels, "le", formatFloat(b.UpperBound), float64(b.Count))
+		}
+		writeSeries(bw, h.Name+"_bucket", h.Labels, "le", "+Inf", float64(h.Count))
+		writeSeries(bw, h.Name+"_sum", h.Labels, "", "", h.Sum)
+		writeSeries(bw, h.Name+"_count", h.Labels, "", "", float64(h.Count))
+	}
+
+	return bw.Flush()
+}
+
+func writeSamples(w *bufio.Writer, typ string, samples []Sample) {
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].Name < samples[j].Name })
+	for i, s := range samples {
+		if i == 0 || samples[i-1].Name != s.Name {
+			writeType(w, s.Name, typ)
+		}
+		writeSeries(w, s.Name, s.Labels, "", "", s.Value)
+	}
+}
+
+func writeType(w *bufio.Writer, name, typ string) {
+	w.WriteString("# TYPE ")
+	w.WriteString(name)
+	w.WriteByte(' ')
+	w.WriteString(typ)
+	w.WriteByte('\n')
+}
+
+// writeSeries пишет одну строку серии; extraName и extraValue — дополнительная метка, например le.
+func writeSeries(w *bufio.Writer, name string, labels map[string]string, extraName, extraValue string, value float64) {
+	w.WriteString(name)
+
+	pairs := sortedPairs(labels)
+	if extraName != "" {
+		pairs = append(pairs, [2]string{extraName, extraValue})
+	}
+	if len(pairs) > 0 {
+		w.WriteByte('{')
+		for i, pair := range pairs {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(pair[0])
+			w.WriteString(`="`)
+			w.WriteString(labelEscaper.Replace(pair[1]))
+			w.WriteByte('"')
+		}
+		w.WriteByte('}')
+	}
+
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,26 @@
+package metrics
+
+import "time"
+
+// Recorder — запись метрик из сервиса, репозитория и HTTP-слоя без зависимости от реестра.
+type Recorder interface {
+	// Inc увеличивает счётчик name с метками на единицу.
+	Inc(name string, labels ...string)
+	// ObserveDuration добавляет длительность в гистограмму name с границами DefaultLatencyBuckets.
+	ObserveDuration(name string, d time.Duration, labels ...string)
+}
+
+func (r *Registry) Inc(name string, labels ...string) {
+	r.Counter(name, labels...).Inc()
+}
+
+func (r *Registry) ObserveDuration(name string, d time.Duration, labels ...string) {
+	r.Histogram(name, DefaultLatencyBuckets, labels...).Observe(d.Seconds())
+}
+
+// Nop — Recorder, который ничего не записывает.
+type Nop struct{}
+
+func (Nop) Inc(string, ...string) {}
+
+func (Nop) ObserveDuration(string, time.Duration, ...string) {}
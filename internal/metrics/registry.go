@@ -0,0 +1,273 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultLatencyBuckets — границы гистограмм задержек в секундах.
+var DefaultLatencyBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// Registry хранит именованные метрики процесса. Метки передаются парами ключ-значение.
+type Registry struct {
+	mu         sync.RWMutex
+	counters   map[string]*Counter
+	gauges     map[string]*Gauge
+	histograms map[string]*Histogram
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		counters:   make(map[string]*Counter),
+		gauges:     make(map[string]*Gauge),
+		histograms: make(map[string]*Histogram),
+	}
+}
+
+// Counter возвращает счётчик с указанным именем и метками, создавая его при первом обращении.
+func (r *Registry) Counter(name string, labels ...string) *Counter {
+	key, l := seriesKey(name, labels)
+
+	r.mu.RLock()
+	c, ok := r.counters[key]
+	r.mu.RUnlock()
+	if ok {
+		return c
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if c, ok = r.counters[key]; !ok {
+		c = &Counter{name: name, labels: l}
+		r.counters[key] = c
+	}
+	return c
+}
+
+// Gauge возвращает gauge с указанным именем и метками, создавая его при первом обращении.
+func (r *Registry) Gauge(name string, labels ...string) *Gauge {
+	key, l := seriesKey(name, labels)
+
+	r.mu.RLock()
+	g, ok := r.gauges[key]
+	r.mu.RUnlock()
+	if ok {
+		return g
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if g, ok = r.gauges[key]; !ok {
+		g = &Gauge{name: name, labels: l}
+		r.gauges[key] = g
+	}
+	return g
+}
+
+// Histogram возвращает гистограмму; buckets учитываются только при её создании.
+func (r *Registry) Histogram(name string, buckets []float64, labels ...string) *Histogram {
+	key, l := seriesKey(name, labels)
+
+	r.mu.RLock()
+	h, ok := r.histograms[key]
+	r.mu.RUnlock()
+	if ok {
+		return h
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if h, ok = r.histograms[key]; !ok {
+		h = newHistogram(name, l, buckets)
+		r.histograms[key] = h
+	}
+	return h
+}
+
+// Snapshot возвращает текущие значения всех метрик, отсортированные по имени и меткам.
+func (r *Registry) Snapshot() Snapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var s Snapshot
+	for _, c := range r.counters {
+		s.Counters = append(s.Counters, Sample{Name: c.name, Labels: c.labels, Value: float64(c.Value())})
+	}
+	for _, g := range r.gauges {
+		s.Gauges = append(s.Gauges, Sample{Name: g.name, Labels: g.labels, Value: g.Value()})
+	}
+	for _, h := range r.histograms {
+		s.Histograms = append(s.Histograms, h.Snapshot())
+	}
+
+	sortSamples(s.Counters)
+	sortSamples(s.Gauges)
+	sort.Slice(s.Histograms, func(i, j int) bool {
+		return s.Histograms[i].key() < s.Histograms[j].key()
+	})
+	return s
+}
+
+type Snapshot struct {
+	Counters   []Sample            `json:"counters"`
+	Gauges     []Sample            `json:"gauges"`
+	Histograms []HistogramSnapshot `json:"histograms"`
+}
+
+type Sample struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+}
+
+type Counter struct {
+	name   string
+	labels map[string]string
+	value  atomic.Int64
+}
+
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+func (c *Counter) Add(n int64) {
+	c.value.Add(n)
+}
+
+func (c *Counter) Value() int64 {
+	return c.value.Load()
+}
+
+type Gauge struct {
+	name   string
+	labels map[string]string
+	bits   atomic.Uint64
+}
+
+func (g *Gauge) Set(v float64) {
+	g.bits.Store(math.Float64bits(v))
+}
+
+func (g *Gauge) Value() float64 {
+	return math.Float64frombits(g.bits.Load())
+}
+
+type Histogram struct {
+	name    string
+	labels  map[string]string
+	buckets []float64
+
+	mu     sync.Mutex
+	counts []int64 // counts[i] — наблюдения <= buckets[i]; последний элемент — +Inf
+	count  int64
+	sum    float64
+}
+
+func newHistogram(name string, labels map[string]string, buckets []float64) *Histogram {
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	return &Histogram{
+		name:    name,
+		labels:  labels,
+		buckets: b,
+		counts:  make([]int64, len(b)+1),
+	}
+}
+
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.buckets, v)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.counts[i]++
+	h.count++
+	h.sum += v
+}
+
+type HistogramSnapshot struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	// Buckets — накопительные счётчики по верхним границам, как в Prometheus.
+	Buckets []Bucket `json:"buckets"`
+	Count   int64    `json:"count"`
+	Sum     float64  `json:"sum"`
+}
+
+type Bucket struct {
+	UpperBound float64 `json:"le"`
+	Count      int64   `json:"count"`
+}
+
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := HistogramSnapshot{
+		Name:    h.name,
+		Labels:  h.labels,
+		Buckets: make([]Bucket, 0, len(h.buckets)),
+		Count:   h.count,
+		Sum:     h.sum,
+	}
+
+	var cumulative int64
+	for i, le := range h.buckets {
+		cumulative += h.counts[i]
+		s.Buckets = append(s.Buckets, Bucket{UpperBound: le, Count: cumulative})
+	}
+	return s
+}
+
+func (s HistogramSnapshot) key() string {
+	k, _ := seriesKey(s.Name, labelPairs(s.Labels))
+	return k
+}
+
+func seriesKey(name string, labels []string) (string, map[string]string) {
+	if len(labels) == 0 {
+		return name, nil
+	}
+
+	l := make(map[string]string, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		l[labels[i]] = labels[i+1]
+	}
+
+	var b strings.Builder
+	b.WriteString(name)
+	for _, pair := range sortedPairs(l) {
+		b.WriteString("|")
+		b.WriteString(pair[0])
+		b.WriteString("=")
+		b.WriteString(pair[1])
+	}
+	return b.String(), l
+}
+
+func sortedPairs(labels map[string]string) [][2]string {
+	pairs := make([][2]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, [2]string{k, v})
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i][0] < pairs[j][0] })
+	return pairs
+}
+
+func labelPairs(labels map[string]string) []string {
+	out := make([]string, 0, len(labels)*2)
+	for _, pair := range sortedPairs(labels) {
+		out = append(out, pair[0], pair[1])
+	}
+	return out
+}
+
+func sortSamples(samples []Sample) {
+	sort.Slice(samples, func(i, j int) bool {
+		ki, _ := seriesKey(samples[i].Name, labelPairs(samples[i].Labels))
+		kj, _ := seriesKey(samples[j].Name, labelPairs(samples[j].Labels))
+		return ki < kj
+	})
+}
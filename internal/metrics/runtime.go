@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// processStart — время запуска процесса: переменная инициализируется при старте программы,
+// а не при сборке обработчика метрик.
+var processStart = time.Now()
+
+// runtimeCollector снимает метрики рантайма Go в реестр перед каждой выдачей.
+type runtimeCollector struct {
+	registry *Registry
+
+	mu     sync.Mutex
+	lastGC uint32
+}
+
+func newRuntimeCollector(r *Registry) *runtimeCollector {
+	r.Gauge("go_info", "version", runtime.Version()).Set(1)
+	r.Gauge("process_start_time_seconds").Set(float64(processStart.Unix()))
+	return &runtimeCollector{registry: r}
+}
+
+func (c *runtimeCollector) collect() {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	r := c.registry
+	r.Gauge("go_goroutines").Set(float64(runtime.NumGoroutine()))
+	r.Gauge("go_memstats_alloc_bytes").Set(float64(ms.Alloc))
+	r.Gauge("go_memstats_heap_inuse_bytes").Set(float64(ms.HeapInuse))
+	r.Gauge("go_memstats_heap_objects").Set(float64(ms.HeapObjects))
+	r.Gauge("go_memstats_sys_bytes").Set(float64(ms.Sys))
+	r.Gauge("go_memstats_next_gc_bytes").Set(float64(ms.NextGC))
+	r.Gauge("go_memstats_last_gc_time_seconds").Set(float64(ms.LastGC) / float64(time.Second))
+	r.Gauge("go_memstats_gc_cpu_fraction").Set(ms.GCCPUFraction)
+
+	// Счётчик циклов GC догоняет значение рантайма; параллельные выдачи не должны учесть разницу дважды
+	gcCycles := r.Counter("go_gc_cycles_total")
+	c.mu.Lock()
+	if ms.NumGC > c.lastGC {
+		gcCycles.Add(int64(ms.NumGC - c.lastGC))
+		c.lastGC = ms.NumGC
+	}
+	c.mu.Unlock()
+}
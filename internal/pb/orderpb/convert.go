@@ -0,0 +1,142 @@
+package orderpb
+
+import (
+	"github.com/platonso/order-viewer/internal/domain"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// FromDomain переводит domain.Order в protobuf-сообщение.
+func FromDomain(order *domain.Order) *Order {
+	items := make([]*Item, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, &Item{
+			ChrtId:      int64(item.ChrtID),
+			TrackNumber: item.TrackNumber,
+			Price:       int64(item.Price),
+			Rid:         item.RID,
+			Name:        item.Name,
+			Sale:        int64(item.Sale),
+			Size:        item.Size,
+			TotalPrice:  int64(item.TotalPrice),
+			NmId:        int64(item.NmID),
+			Brand:       item.Brand,
+			Status:      int64(item.Status),
+		})
+	}
+
+	return &Order{
+		OrderUid:    order.OrderUID,
+		TrackNumber: order.TrackNumber,
+		Entry:       order.Entry,
+		Delivery: &Delivery{
+			Name:    order.Delivery.Name,
+			Phone:   order.Delivery.Phone,
+			Zip:     order.Delivery.Zip,
+			City:    order.Delivery.City,
+			Address: order.Delivery.Address,
+			Region:  order.Delivery.Region,
+			Email:   order.Delivery.Email,
+		},
+		Payment: &Payment{
+			Transaction:  order.Payment.Transaction,
+			RequestId:    order.Payment.RequestID,
+			Currency:     order.Payment.Currency,
+			Provider:     order.Payment.Provider,
+			Amount:       int64(order.Payment.Amount),
+			PaymentDt:    int64(order.Payment.PaymentDt),
+			Bank:         order.Payment.Bank,
+			DeliveryCost: int64(order.Payment.DeliveryCost),
+			GoodsTotal:   int64(order.Payment.GoodsTotal),
+			CustomFee:    int64(order.Payment.CustomFee),
+		},
+		Items:             items,
+		Locale:            order.Locale,
+		InternalSignature: order.InternalSignature,
+		CustomerId:        order.CustomerID,
+		DeliveryService:   order.DeliveryService,
+		Shardkey:          order.Shardkey,
+		SmId:              int64(order.SmID),
+		DateCreated:       timestamppb.New(order.DateCreated),
+		OofShard:          order.OofShard,
+	}
+}
+
+// SummaryFromDomain переводит краткое представление заказа в protobuf-сообщение.
+func SummaryFromDomain(s domain.OrderSummary) *OrderSummary {
+	return &OrderSummary{
+		OrderUid:        s.OrderUID,
+		TrackNumber:     s.TrackNumber,
+		CustomerId:      s.CustomerID,
+		DeliveryService: s.DeliveryService,
+		DateCreated:     timestamppb.New(s.DateCreated),
+		Amount:          int64(s.Amount),
+		Currency:        s.Currency,
+		ItemsCount:      int64(s.ItemsCount),
+	}
+}
+
+// ToDomain переводит protobuf-сообщение в domain.Order.
+func ToDomain(pb *Order) *domain.Order {
+	order := &domain.Order{
+		OrderUID:          pb.GetOrderUid(),
+		TrackNumber:       pb.GetTrackNumber(),
+		Entry:             pb.GetEntry(),
+		Locale:            pb.GetLocale(),
+		InternalSignature: pb.GetInternalSignature(),
+		CustomerID:        pb.GetCustomerId(),
+		DeliveryService:   pb.GetDeliveryService(),
+		Shardkey:          pb.GetShardkey(),
+		SmID:              int(pb.GetSmId()),
+		OofShard:          pb.GetOofShard(),
+	}
+
+	if pb.GetDateCreated() != nil {
+		order.DateCreated = pb.GetDateCreated().AsTime()
+	}
+
+	if d := pb.GetDelivery(); d != nil {
+		order.Delivery = domain.Delivery{
+			Name:    d.GetName(),
+			Phone:   d.GetPhone(),
+			Zip:     d.GetZip(),
+			City:    d.GetCity(),
+			Address: d.GetAddress(),
+			Region:  d.GetRegion(),
+			Email:   d.GetEmail(),
+		}
+	}
+
+	if p := pb.GetPayment(); p != nil {
+		order.Payment = domain.Payment{
+			Transaction:  p.GetTransaction(),
+			RequestID:    p.GetRequestId(),
+			Currency:     p.GetCurrency(),
+			Provider:     p.GetProvider(),
+			Amount:       int(p.GetAmount()),
+			PaymentDt:    int(p.GetPaymentDt()),
+			Bank:         p.GetBank(),
+			DeliveryCost: int(p.GetDeliveryCost()),
+			GoodsTotal:   int(p.GetGoodsTotal()),
+			CustomFee:    int(p.GetCustomFee()),
+		}
+	}
+
+	for _, item := range pb.GetItems() {
+		order.Items = append(order.Items, domain.Item{
+			ChrtID:      int(item.GetChrtId()),
+			TrackNumber: item.GetTrackNumber(),
+			Price:       int(item.GetPrice()),
+			RID:         item.GetRid(),
+			Name:        item.GetName(),
+			Sale:        int(item.GetSale()),
+			Size:        item.GetSize(),
+			TotalPrice:  int(item.GetTotalPrice()),
+			NmID:        int(item.GetNmId()),
+			Brand:       item.GetBrand(),
+			Status:      int(item.GetStatus()),
+		})
+	}
+
+	return order
+}
@@ -0,0 +1,3 @@
+package orderpb
+
+//go:generate protoc -I ../../../api/proto --go_out=../../.. --go_opt=module=github.com/platonso/order-viewer --go-grpc_out=../../.. --go-grpc_opt=module=github.com/platonso/order-viewer order.proto order_service.proto
@@ -0,0 +1,116 @@
+package pii
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/platonso/order-viewer/internal/domain"
+)
+
+// Strategy определяет, как маскируется значение поля.
+type Strategy string
+
+const (
+	StrategyOff     Strategy = "off"     // значение отдаётся как есть
+	StrategyFull    Strategy = "full"    // значение полностью заменяется на ***
+	StrategyPartial Strategy = "partial" // сохраняется первый символ: И***
+	StrategyPhone   Strategy = "phone"   // сохраняются код страны и последние 4 цифры: +7***6789
+	StrategyEmail   Strategy = "email"   // сохраняются первый символ и домен: t***@example.com
+)
+
+// Поля заказа с персональными данными.
+const (
+	FieldDeliveryName    = "delivery.name"
+	FieldDeliveryPhone   = "delivery.phone"
+	FieldDeliveryAddress = "delivery.address"
+	FieldDeliveryEmail   = "delivery.email"
+)
+
+const mask = "***"
+
+// Policy сопоставляет полю стратегию маскирования; отсутствующие поля не маскируются.
+type Policy map[string]Strategy
+
+// NewPolicy разбирает стратегии маскирования из строковых значений конфигурации.
+func NewPolicy(strategies map[string]string) (Policy, error) {
+	policy := make(Policy, len(strategies))
+	for field, value := range strategies {
+		strategy := Strategy(value)
+		switch strategy {
+		case StrategyOff, StrategyFull, StrategyPartial, StrategyPhone, StrategyEmail:
+			policy[field] = strategy
+		default:
+			return nil, fmt.Errorf("invalid mask strategy %q for field %s (expected off, full, partial, phone or email)", value, field)
+		}
+	}
+	return policy, nil
+}
+
+// Redact возвращает копию заказа с замаскированными персональными данными.
+// Исходный заказ не меняется: он может быть общим с кешем.
+func (p Policy) Redact(order *domain.Order) *domain.Order {
+	if order == nil {
+		return nil
+	}
+
+	redacted := *order
+	d := &redacted.Delivery
+	d.Name = p.apply(FieldDeliveryName, d.Name)
+	d.Phone = p.apply(FieldDeliveryPhone, d.Phone)
+	d.Address = p.apply(FieldDeliveryAddress, d.Address)
+	d.Email = p.apply(FieldDeliveryEmail, d.Email)
+	return &redacted
+}
+
+func (p Policy) apply(field, value string) string {
+	if value == "" {
+		return value
+	}
+
+	switch p[field] {
+	case StrategyFull:
+		return mask
+	case StrategyPartial:
+		return maskPartial(value)
+	case StrategyPhone:
+		return maskPhone(value)
+	case StrategyEmail:
+		return maskEmail(value)
+	default:
+		return value
+	}
+}
+
+func maskPartial(value string) string {
+	r, _ := utf8.DecodeRuneInString(value)
+	return string(r) + mask
+}
+
+// maskPhone оставляет «+» и первую цифру кода страны, а также последние 4 цифры.
+// Короткие номера маскируются целиком, чтобы не раскрыть большую их часть.
+func maskPhone(value string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, value)
+	if len(digits) < 8 {
+		return mask
+	}
+
+	prefix := digits[:1]
+	if strings.HasPrefix(strings.TrimSpace(value), "+") {
+		prefix = "+" + prefix
+	}
+	return prefix + mask + digits[len(digits)-4:]
+}
+
+func maskEmail(value string) string {
+	at := strings.LastIndexByte(value, '@')
+	if at <= 0 {
+		return maskPartial(value)
+	}
+	return maskPartial(value[:at]) + value[at:]
+}
@@ -0,0 +1,161 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rate — пополнение корзины: Count токенов за Per, не больше Burst в запасе.
+type Rate struct {
+	Count int
+	Per   time.Duration
+	Burst int
+}
+
+// ParseRate разбирает лимит в формате count/unit[:burst], например 20/s, 100/m:200.
+// Без burst ёмкость корзины равна count.
+func ParseRate(s string) (Rate, error) {
+	spec, burstStr, hasBurst := strings.Cut(strings.TrimSpace(s), ":")
+	countStr, unit, ok := strings.Cut(spec, "/")
+	if !ok {
+		return Rate{}, fmt.Errorf("invalid rate %q (expected count/unit[:burst])", s)
+	}
+
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count <= 0 {
+		return Rate{}, fmt.Errorf("invalid rate %q: count must be a positive integer", s)
+	}
+
+	var per time.Duration
+	switch unit {
+	case "s":
+		per = time.Second
+	case "m":
+		per = time.Minute
+	case "h":
+		per = time.Hour
+	default:
+		return Rate{}, fmt.Errorf("invalid rate %q: unit must be s, m or h", s)
+	}
+
+	burst := count
+	if hasBurst {
+		burst, err = strconv.Atoi(burstStr)
+		if err != nil || burst <= 0 {
+			return Rate{}, fmt.Errorf("invalid rate %q: burst must be a positive integer", s)
+		}
+	}
+
+	return Rate{Count: count, Per: per, Burst: burst}, nil
+}
+
+// perSecond — скорость пополнения корзины в токенах в секунду.
+func (r Rate) perSecond() float64 {
+	return float64(r.Count) / r.Per.Seconds()
+}
+
+// Result — состояние корзины после проверки.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter — через сколько появится следующий токен; ноль, если токены есть
+	RetryAfter time.Duration
+	// Reset — через сколько корзина наполнится целиком
+	Reset time.Duration
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter — набор корзин токенов с общим лимитом, по одной на ключ клиента.
+type Limiter struct {
+	rate Rate
+	now  func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewLimiter(rate Rate) *Limiter {
+	return &Limiter{
+		rate:    rate,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+func (l *Limiter) Rate() Rate {
+	return l.rate
+}
+
+// Allow списывает токен из корзины клиента key, если он есть.
+func (l *Limiter) Allow(key string) Result {
+	return l.take(key, 1, false)
+}
+
+// Peek возвращает состояние корзины, не списывая токен. Allowed означает, что токен есть.
+func (l *Limiter) Peek(key string) Result {
+	return l.take(key, 0, false)
+}
+
+// Charge списывает n токенов за уже выполненную работу, даже если их не хватает:
+// корзина уходит в минус, и следующие запросы клиента отклоняются, пока долг не погашен.
+func (l *Limiter) Charge(key string, n int) Result {
+	return l.take(key, float64(n), true)
+}
+
+func (l *Limiter) take(key string, n float64, force bool) Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.rate.Burst), last: now}
+		l.buckets[key] = b
+	}
+
+	perSecond := l.rate.perSecond()
+	b.tokens = math.Min(float64(l.rate.Burst), b.tokens+now.Sub(b.last).Seconds()*perSecond)
+	b.last = now
+
+	res := Result{Limit: l.rate.Burst, Allowed: b.tokens >= 1}
+	if res.Allowed || force {
+		b.tokens -= n
+	}
+	if !res.Allowed {
+		res.RetryAfter = seconds((1 - b.tokens) / perSecond)
+	}
+	res.Remaining = max(0, int(b.tokens))
+	res.Reset = seconds((float64(l.rate.Burst) - b.tokens) / perSecond)
+	return res
+}
+
+// sweep удаляет корзины, которые успели наполниться целиком: они неотличимы от новых.
+func (l *Limiter) sweep(now time.Time) {
+	fill := seconds(float64(l.rate.Burst) / l.rate.perSecond())
+	if now.Sub(l.lastSweep) < fill {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= fill {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/platonso/order-viewer/internal/domain"
+)
+
+// FindByIDs загружает несколько заказов одним запросом; связанные таблицы собираются в JSON.
+// Заказы, которых нет в базе, в результат не попадают.
+func (r *PostgresRepo) FindByIDs(ctx context.Context, orderUIDs []string) (map[string]*domain.Order, error) {
+	defer r.observeQuery("order_find_batch", time.Now())
+
+	batchQuery := `
+		SELECT o.order_uid, o.track_number, o.entry, o.locale, o.internal_signature, o.customer_id,
+		       o.delivery_service, o.shardkey, o.sm_id, o.date_created, o.oof_shard, o.warnings,
+		       (SELECT row_to_json(d) FROM (
+		           SELECT name, phone, zip, city, address, region, email
+		           FROM deliveries WHERE order_uid = o.order_uid LIMIT 1) d),
+		       (SELECT row_to_json(p) FROM (
+		           SELECT transaction, request_id, currency, provider, amount, payment_dt,
+		                  bank, delivery_cost, goods_total, custom_fee
+		           FROM payments WHERE order_uid = o.order_uid LIMIT 1) p),
+		       (SELECT COALESCE(json_agg(i), '[]') FROM (
+		           SELECT chrt_id, track_number, price, rid, name, sale, size,
+		                  total_price, nm_id, brand, status
+		           FROM items WHERE order_uid = o.order_uid) i)
+		FROM orders o
+		WHERE o.order_uid = ANY($1)
+`
+
+	rows, err := r.DB.Query(ctx, batchQuery, orderUIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := make(map[string]*domain.Order, len(orderUIDs))
+	for rows.Next() {
+		var (
+			order                              domain.Order
+			warnings, delivery, payment, items []byte
+		)
+		err := rows.Scan(
+			&order.OrderUID,
+			&order.TrackNumber,
+			&order.Entry,
+			&order.Locale,
+			&order.InternalSignature,
+			&order.CustomerID,
+			&order.DeliveryService,
+			&order.Shardkey,
+			&order.SmID,
+			&order.DateCreated,
+			&order.OofShard,
+			&warnings,
+			&delivery,
+			&payment,
+			&items,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+
+		for _, part := range []struct {
+			data []byte
+			dst  any
+		}{
+			{warnings, &order.Warnings},
+			{delivery, &order.Delivery},
+			{payment, &order.Payment},
+			{items, &order.Items},
+		} {
+			if part.data == nil {
+				continue
+			}
+			if err := json.Unmarshal(part.data, part.dst); err != nil {
+				return nil, fmt.Errorf("failed to decode order %s: %w", order.OrderUID, err)
+			}
+		}
+
+		orders[order.OrderUID] = &order
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating orders: %w", err)
+	}
+
+	return orders, nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/platonso/order-viewer/internal/domain"
+)
+
+// List возвращает до filter.Limit кратких представлений заказов, начиная после filter.After.
+func (r *PostgresRepo) List(ctx context.Context, filter domain.OrderFilter) ([]domain.OrderSummary, error) {
+	defer r.observeQuery("order_list", time.Now())
+
+	var (
+		conds []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.CustomerID != "" {
+		conds = append(conds, "o.customer_id = "+arg(filter.CustomerID))
+	}
+	if filter.TrackNumber != "" {
+		conds = append(conds, "o.track_number = "+arg(filter.TrackNumber))
+	}
+	if filter.DeliveryService != "" {
+		conds = append(conds, "o.delivery_service = "+arg(filter.DeliveryService))
+	}
+	if filter.Brand != "" {
+		conds = append(conds, "EXISTS (SELECT 1 FROM items i WHERE i.order_uid = o.order_uid AND i.brand = "+arg(filter.Brand)+")")
+	}
+	if !filter.From.IsZero() {
+		conds = append(conds, "o.date_created >= "+arg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		conds = append(conds, "o.date_created < "+arg(filter.To))
+	}
+
+	var orderBy string
+	switch filter.Sort {
+	case domain.SortDateAsc:
+		orderBy = "o.date_created ASC, o.order_uid ASC"
+		if c := filter.After; c != nil {
+			conds = append(conds, fmt.Sprintf("(o.date_created, o.order_uid) > (%s, %s)", arg(c.DateCreated), arg(c.OrderUID)))
+		}
+	case domain.SortOrderUIDAsc:
+		orderBy = "o.order_uid ASC"
+		if c := filter.After; c != nil {
+			conds = append(conds, "o.order_uid > "+arg(c.OrderUID))
+		}
+	case domain.SortOrderUIDDesc:
+		orderBy = "o.order_uid DESC"
+		if c := filter.After; c != nil {
+			conds = append(conds, "o.order_uid < "+arg(c.OrderUID))
+		}
+	default:
+		orderBy = "o.date_created DESC, o.order_uid DESC"
+		if c := filter.After; c != nil {
+			conds = append(conds, fmt.Sprintf("(o.date_created, o.order_uid) < (%s, %s)", arg(c.DateCreated), arg(c.OrderUID)))
+		}
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+
+	listQuery := fmt.Sprintf(`
+		SELECT o.order_uid, o.track_number, o.customer_id, o.delivery_service, o.date_created,
+		       COALESCE(p.amount, 0), COALESCE(p.currency, ''),
+		       (SELECT count(*) FROM items i WHERE i.order_uid = o.order_uid)
+		FROM orders o
+		LEFT JOIN payments p ON p.order_uid = o.order_uid
+		%s
+		ORDER BY %s
+		LIMIT %s
+`, where, orderBy, arg(filter.Limit))
+
+	rows, err := r.DB.Query(ctx, listQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orders: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := make([]domain.OrderSummary, 0, filter.Limit)
+	for rows.Next() {
+		var s domain.OrderSummary
+		if err := rows.Scan(
+			&s.OrderUID,
+			&s.TrackNumber,
+			&s.CustomerID,
+			&s.DeliveryService,
+			&s.DateCreated,
+			&s.Amount,
+			&s.Currency,
+			&s.ItemsCount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan order summary: %w", err)
+		}
+		summaries = append(summaries, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating orders: %w", err)
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/platonso/order-viewer/internal/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func insertOrderCreatedEvent(ctx context.Context, tx pgx.Tx, order *domain.Order) error {
+	payload, err := json.Marshal(domain.OrderCreatedEvent{
+		EventType:  domain.EventOrderCreated,
+		OrderUID:   order.OrderUID,
+		OccurredAt: time.Now().UTC(),
+		Order:      order,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox event: %w", err)
+	}
+
+	// Контекст трассировки сохраняется, чтобы публикация события продолжила трассировку запроса
+	var traceContext []byte
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) > 0 {
+		if traceContext, err = json.Marshal(carrier); err != nil {
+			return fmt.Errorf("failed to marshal trace context: %w", err)
+		}
+	}
+
+	outboxQuery := `
+		INSERT INTO outbox (aggregate_id, event_type, payload, trace_context)
+		VALUES ($1, $2, $3, $4)
+`
+	_, err = tx.Exec(ctx, outboxQuery, order.OrderUID, domain.EventOrderCreated, payload, traceContext)
+	if err != nil {
+		return fmt.Errorf("failed to insert outbox event: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepo) ProcessOutbox(ctx context.Context, limit int, publish func(ctx context.Context, events []domain.OutboxEvent) error) (int, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// SKIP LOCKED позволяет нескольким экземплярам сервиса разбирать outbox параллельно
+	selectQuery := `
+		SELECT id, aggregate_id, event_type, payload, created_at, trace_context
+		FROM outbox
+		WHERE published_at IS NULL
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+`
+	start := time.Now()
+	rows, err := tx.Query(ctx, selectQuery, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query outbox: %w", err)
+	}
+
+	var events []domain.OutboxEvent
+	for rows.Next() {
+		var (
+			event        domain.OutboxEvent
+			traceContext []byte
+		)
+		if err := rows.Scan(
+			&event.ID,
+			&event.AggregateID,
+			&event.EventType,
+			&event.Payload,
+			&event.CreatedAt,
+			&traceContext,
+		); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		// Повреждённый контекст трассировки не мешает публикации события
+		if traceContext != nil {
+			_ = json.Unmarshal(traceContext, &event.TraceContext)
+		}
+		events = append(events, event)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating outbox: %w", err)
+	}
+	r.observeQuery("outbox_select", start)
+
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	if err := publish(ctx, events); err != nil {
+		return 0, err
+	}
+
+	ids := make([]int64, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+
+	updateQuery := `
+		UPDATE outbox SET published_at = now()
+		WHERE id = ANY($1)
+`
+	start = time.Now()
+	if _, err := tx.Exec(ctx, updateQuery, ids); err != nil {
+		return 0, fmt.Errorf("failed to mark outbox events as published: %w", err)
+	}
+	r.observeQuery("outbox_mark_published", start)
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit: %w", err)
+	}
+
+	return len(events), nil
+}
@@ -2,20 +2,33 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/platonso/order-viewer/internal/domain"
+	"github.com/platonso/order-viewer/internal/metrics"
+	"time"
 )
 
+// metricQueryDuration — длительность запросов к бд с меткой query.
+const metricQueryDuration = "db_query_duration_seconds"
+
 type PostgresRepo struct {
-	DB *pgxpool.Pool
+	DB      *pgxpool.Pool
+	metrics metrics.Recorder
 }
 
-func NewPostgresRepo(ctx context.Context, connStr string) (*PostgresRepo, error) {
-	db, err := pgxpool.New(ctx, connStr)
+func NewPostgresRepo(ctx context.Context, connStr string, recorder metrics.Recorder) (*PostgresRepo, error) {
+	poolConfig, err := pgxpool.ParseConfig(connStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database config: %w", err)
+	}
+	poolConfig.ConnConfig.Tracer = queryTracer{}
+
+	db, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -25,7 +38,12 @@ func NewPostgresRepo(ctx context.Context, connStr string) (*PostgresRepo, error)
 		return nil, fmt.Errorf("database ping failed: %w", err)
 	}
 
-	return &PostgresRepo{DB: db}, nil
+	return &PostgresRepo{DB: db, metrics: recorder}, nil
+}
+
+// observeQuery записывает длительность запроса query, начатого в start.
+func (r *PostgresRepo) observeQuery(query string, start time.Time) {
+	r.metrics.ObserveDuration(metricQueryDuration, time.Since(start), "query", query)
 }
 
 func (r *PostgresRepo) Save(ctx context.Context, order *domain.Order) error {
@@ -35,11 +53,19 @@ func (r *PostgresRepo) Save(ctx context.Context, order *domain.Order) error {
 	}
 	defer tx.Rollback(ctx)
 
+	var warnings []byte
+	if len(order.Warnings) > 0 {
+		if warnings, err = json.Marshal(order.Warnings); err != nil {
+			return fmt.Errorf("failed to marshal order warnings: %w", err)
+		}
+	}
+
 	orderQuery := `
 		INSERT INTO orders (order_uid,track_number, entry, locale, internal_signature, 
-        	customer_id, delivery_service, shardkey, sm_id,date_created, oof_shard)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+        	customer_id, delivery_service, shardkey, sm_id,date_created, oof_shard, warnings)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 `
+	start := time.Now()
 	_, err = tx.Exec(ctx, orderQuery,
 		order.OrderUID,
 		order.TrackNumber,
@@ -52,6 +78,7 @@ func (r *PostgresRepo) Save(ctx context.Context, order *domain.Order) error {
 		order.SmID,
 		order.DateCreated,
 		order.OofShard,
+		warnings,
 	)
 	if err != nil {
 		// Проверка, является ли ошибка PostgreSQL ошибкой уникальности (23505 - unique_violation)
@@ -61,11 +88,13 @@ func (r *PostgresRepo) Save(ctx context.Context, order *domain.Order) error {
 		}
 		return fmt.Errorf("failed to insert order: %w", err)
 	}
+	r.observeQuery("order_insert", start)
 
 	deliveryQuery := `
 		INSERT INTO deliveries (order_uid, name, phone, zip, city, address, region, email)
 		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
 `
+	start = time.Now()
 	_, err = tx.Exec(ctx, deliveryQuery,
 		order.OrderUID,
 		order.Delivery.Name,
@@ -80,12 +109,14 @@ func (r *PostgresRepo) Save(ctx context.Context, order *domain.Order) error {
 	if err != nil {
 		return fmt.Errorf("failed to insert delivery: %w", err)
 	}
+	r.observeQuery("delivery_insert", start)
 
 	paymentQuery := `
 		INSERT INTO payments (order_uid, transaction, request_id, currency, provider, 
 			 amount, payment_dt, bank, delivery_cost, goods_total, custom_fee)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 `
+	start = time.Now()
 	_, err = tx.Exec(ctx, paymentQuery,
 		order.OrderUID,
 		order.Payment.Transaction,
@@ -102,6 +133,7 @@ func (r *PostgresRepo) Save(ctx context.Context, order *domain.Order) error {
 	if err != nil {
 		return fmt.Errorf("failed to insert payment: %w", err)
 	}
+	r.observeQuery("payment_insert", start)
 
 	itemQuery := `
 		INSERT INTO items (order_uid, chrt_id, track_number, price, rid, name, 
@@ -109,6 +141,7 @@ func (r *PostgresRepo) Save(ctx context.Context, order *domain.Order) error {
     	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 `
 	for _, item := range order.Items {
+		start = time.Now()
 		_, err = tx.Exec(ctx, itemQuery,
 			order.OrderUID,
 			item.ChrtID,
@@ -126,11 +159,21 @@ func (r *PostgresRepo) Save(ctx context.Context, order *domain.Order) error {
 		if err != nil {
 			return fmt.Errorf("failed to insert item: %w", err)
 		}
+		r.observeQuery("item_insert", start)
+	}
+
+	// Событие пишется в outbox в той же транзакции, что и заказ
+	start = time.Now()
+	if err := insertOrderCreatedEvent(ctx, tx, order); err != nil {
+		return err
 	}
+	r.observeQuery("outbox_insert", start)
 
+	start = time.Now()
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("failed to commit: %w", err)
 	}
+	r.observeQuery("order_commit", start)
 
 	return nil
 }
@@ -138,12 +181,16 @@ func (r *PostgresRepo) Save(ctx context.Context, order *domain.Order) error {
 func (r *PostgresRepo) FindByID(ctx context.Context, orderUID string) (*domain.Order, error) {
 	orderQuery := `
 		SELECT order_uid, track_number, entry, locale, internal_signature, customer_id,
-		       delivery_service, shardkey, sm_id, date_created, oof_shard
+		       delivery_service, shardkey, sm_id, date_created, oof_shard, warnings
 		FROM orders 
 		WHERE order_uid = $1
 	`
 
-	var order domain.Order
+	var (
+		order    domain.Order
+		warnings []byte
+	)
+	start := time.Now()
 	err := r.DB.QueryRow(ctx, orderQuery, orderUID).Scan(
 		&order.OrderUID,
 		&order.TrackNumber,
@@ -156,7 +203,10 @@ func (r *PostgresRepo) FindByID(ctx context.Context, orderUID string) (*domain.O
 		&order.SmID,
 		&order.DateCreated,
 		&order.OofShard,
+		&warnings,
 	)
+	// Промахи тоже учитываются: поиск несуществующих заказов идёт мимо кеша
+	r.observeQuery("order_select", start)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrOrderNotFound
@@ -164,12 +214,19 @@ func (r *PostgresRepo) FindByID(ctx context.Context, orderUID string) (*domain.O
 		return nil, fmt.Errorf("failed to query order: %w", err)
 	}
 
+	if warnings != nil {
+		if err := json.Unmarshal(warnings, &order.Warnings); err != nil {
+			return nil, fmt.Errorf("failed to decode order warnings: %w", err)
+		}
+	}
+
 	deliveryQuery := `
 		SELECT name, phone, zip, city, address, region, email
 		FROM deliveries
 		WHERE order_uid = $1
 `
 
+	start = time.Now()
 	err = r.DB.QueryRow(ctx, deliveryQuery, orderUID).Scan(
 		&order.Delivery.Name,
 		&order.Delivery.Phone,
@@ -182,6 +239,7 @@ func (r *PostgresRepo) FindByID(ctx context.Context, orderUID string) (*domain.O
 	if err != nil {
 		return nil, fmt.Errorf("failed to query delivery: %w", err)
 	}
+	r.observeQuery("delivery_select", start)
 
 	paymentQuery := `
 		SELECT p.transaction, p.request_id, p.currency, p.provider, p.amount, p.payment_dt, 
@@ -190,6 +248,7 @@ func (r *PostgresRepo) FindByID(ctx context.Context, orderUID string) (*domain.O
 		WHERE order_uid = $1
 `
 
+	start = time.Now()
 	err = r.DB.QueryRow(ctx, paymentQuery, orderUID).Scan(
 		&order.Payment.Transaction,
 		&order.Payment.RequestID,
@@ -205,6 +264,7 @@ func (r *PostgresRepo) FindByID(ctx context.Context, orderUID string) (*domain.O
 	if err != nil {
 		return nil, fmt.Errorf("failed to query payment: %w", err)
 	}
+	r.observeQuery("payment_select", start)
 
 	itemsQuery := `
 		SELECT i.chrt_id, i.track_number, i.price, i.rid, i.name, i.sale, i.size, 
@@ -213,6 +273,7 @@ func (r *PostgresRepo) FindByID(ctx context.Context, orderUID string) (*domain.O
 		WHERE order_uid = $1
 	`
 
+	start = time.Now()
 	rows, err := r.DB.Query(ctx, itemsQuery, orderUID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query items: %w", err)
@@ -244,6 +305,7 @@ func (r *PostgresRepo) FindByID(ctx context.Context, orderUID string) (*domain.O
 	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating items: %w", err)
 	}
+	r.observeQuery("items_select", start)
 
 	order.Items = items
 
@@ -8,6 +8,8 @@ import (
 type DBRepository interface {
 	Save(ctx context.Context, order *domain.Order) error
 	FindByID(ctx context.Context, orderUID string) (*domain.Order, error)
+	FindByIDs(ctx context.Context, orderUIDs []string) (map[string]*domain.Order, error)
+	List(ctx context.Context, filter domain.OrderFilter) ([]domain.OrderSummary, error)
 	Close()
 }
 
@@ -15,3 +17,9 @@ type CacheRepository interface {
 	Save(order *domain.Order)
 	FindByID(orderUID string) (*domain.Order, bool)
 }
+
+type OutboxRepository interface {
+	// ProcessOutbox блокирует пачку неопубликованных событий, передаёт их в publish
+	// и помечает опубликованными, только если publish завершился без ошибки.
+	ProcessOutbox(ctx context.Context, limit int, publish func(ctx context.Context, events []domain.OutboxEvent) error) (int, error)
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/platonso/order-viewer/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = tracing.Tracer("internal/repository")
+
+// queryTracer создаёт спан на каждый запрос pgx, в том числе BEGIN и COMMIT транзакций.
+// Для Query спан завершается при закрытии rows, то есть включает чтение строк.
+type queryTracer struct{}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	operation, table := describeQuery(data.SQL)
+
+	name := operation
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemNamePostgreSQL,
+		semconv.DBOperationName(operation),
+		semconv.DBQueryText(strings.Join(strings.Fields(data.SQL), " ")),
+	}
+	if table != "" {
+		name += " " + table
+		attrs = append(attrs, semconv.DBCollectionName(table))
+	}
+
+	ctx, _ = tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	return ctx
+}
+
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	// Отсутствие строки — обычный ответ на поиск, а не сбой запроса
+	if data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows) {
+		tracing.Fail(span, data.Err)
+	}
+}
+
+var queryTable = regexp.MustCompile(`(?i)\b(?:from|into|update)\s+([a-z_][a-z0-9_]*)`)
+
+// describeQuery возвращает операцию и основную таблицу запроса для имени спана.
+// Таблица ищется вне скобок, чтобы подзапросы и списки колонок её не подменяли.
+func describeQuery(sql string) (operation, table string) {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "", ""
+	}
+	operation = strings.ToUpper(fields[0])
+
+	var top strings.Builder
+	depth := 0
+	for _, r := range sql {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case depth == 0:
+			top.WriteRune(r)
+		}
+	}
+
+	if m := queryTable.FindStringSubmatch(top.String()); m != nil {
+		table = m[1]
+	}
+	return operation, table
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/platonso/order-viewer/internal/domain"
+)
+
+// replaySize — сколько последних событий хранится для возобновления по Last-Event-ID.
+const replaySize = 1024
+
+// ErrSubscriberLagged — подписчик не успевал забирать события и был отключён.
+// После переподключения с последним полученным ID пропущенные события досылаются из буфера.
+var ErrSubscriberLagged = errors.New("subscriber is too slow")
+
+// ErrServiceStopped — сервис останавливается; клиент переподключается к другому экземпляру.
+var ErrServiceStopped = errors.New("order service is stopping")
+
+// broadcaster рассылает сохранённые заказы подписчикам. Медленный подписчик
+// не задерживает сохранение: при переполнении буфера он отключается с ErrSubscriberLagged.
+type broadcaster struct {
+	// epoch отличает ID событий этого процесса от ID, выданных до перезапуска
+	epoch string
+
+	mu      sync.Mutex
+	seq     uint64
+	replay  []domain.OrderEvent // кольцевой буфер последних событий
+	subs    map[*Subscription]struct{}
+	stopped bool
+}
+
+func newBroadcaster() *broadcaster {
+	return &broadcaster{
+		epoch:  strconv.FormatInt(time.Now().UnixNano(), 36),
+		replay: make([]domain.OrderEvent, 0, replaySize),
+		subs:   make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscription — подписка на ленту заказов.
+type Subscription struct {
+	b      *broadcaster
+	events chan domain.OrderEvent
+	err    error
+	// resetID — ID, с которого продолжается лента, если lastEventID не удалось возобновить
+	resetID string
+}
+
+// Events закрывается после Close или при отключении медленного подписчика.
+func (s *Subscription) Events() <-chan domain.OrderEvent {
+	return s.events
+}
+
+// Err возвращает причину закрытия канала событий; nil, если подписка закрыта через Close.
+// Вызывается после того, как канал Events закрыт.
+func (s *Subscription) Err() error {
+	return s.err
+}
+
+// Reset сообщает, что лента не возобновлена с lastEventID: событие старше буфера
+// или выдано до перезапуска, и часть заказов пропущена. Клиенту нужно заново загрузить
+// список заказов, а eventID заменяет его последний ID.
+func (s *Subscription) Reset() (eventID string, ok bool) {
+	return s.resetID, s.resetID != ""
+}
+
+func (s *Subscription) Close() {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	s.b.remove(s, nil)
+}
+
+// subscribe регистрирует подписчика. Если lastEventID выдан этим процессом и следующее
+// за ним событие ещё в буфере, в канал сначала попадают пропущенные события.
+// Иначе непустой lastEventID помечает подписку как сброшенную.
+func (b *broadcaster) subscribe(lastEventID string, buffer int) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var (
+		backlog []domain.OrderEvent
+		resetID string
+	)
+	// Первое событие в буфере имеет номер b.seq-len(b.replay)+1
+	if seq, ok := b.parseID(lastEventID); ok && seq >= b.seq-uint64(len(b.replay)) && seq <= b.seq {
+		for _, e := range b.replayed() {
+			if s, _ := b.parseID(e.ID); s > seq {
+				backlog = append(backlog, e)
+			}
+		}
+	} else if lastEventID != "" {
+		resetID = b.eventID(b.seq)
+	}
+
+	sub := &Subscription{b: b, events: make(chan domain.OrderEvent, buffer+len(backlog)), resetID: resetID}
+	for _, e := range backlog {
+		sub.events <- e
+	}
+	b.subs[sub] = struct{}{}
+	if b.stopped {
+		b.remove(sub, ErrServiceStopped)
+	}
+	return sub
+}
+
+// stop отключает всех подписчиков с ErrServiceStopped; новые подписки сразу закрываются.
+func (b *broadcaster) stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.stopped = true
+	for sub := range b.subs {
+		b.remove(sub, ErrServiceStopped)
+	}
+}
+
+func (b *broadcaster) publish(order *domain.Order) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	event := domain.OrderEvent{ID: b.eventID(b.seq), Order: order}
+	if len(b.replay) < replaySize {
+		b.replay = append(b.replay, event)
+	} else {
+		b.replay[(b.seq-1)%replaySize] = event
+	}
+
+	for sub := range b.subs {
+		select {
+		case sub.events <- event:
+		default:
+			b.remove(sub, ErrSubscriberLagged)
+		}
+	}
+}
+
+// remove отписывает подписчика; вызывается под b.mu.
+func (b *broadcaster) remove(sub *Subscription, err error) {
+	if _, ok := b.subs[sub]; !ok {
+		return
+	}
+	delete(b.subs, sub)
+	sub.err = err
+	close(sub.events)
+}
+
+// replayed возвращает события буфера в порядке публикации; вызывается под b.mu.
+func (b *broadcaster) replayed() []domain.OrderEvent {
+	if len(b.replay) < replaySize {
+		return b.replay
+	}
+	start := int(b.seq % replaySize)
+	return append(append([]domain.OrderEvent(nil), b.replay[start:]...), b.replay[:start]...)
+}
+
+func (b *broadcaster) eventID(seq uint64) string {
+	return b.epoch + "-" + strconv.FormatUint(seq, 10)
+}
+
+// parseID разбирает ID события этого процесса; ID другого процесса или неверный ID не возобновляют ленту.
+func (b *broadcaster) parseID(id string) (uint64, bool) {
+	epoch, seq, ok := strings.Cut(id, "-")
+	if !ok || epoch != b.epoch {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/platonso/order-viewer/internal/domain"
+)
+
+func TestBroadcasterResume(t *testing.T) {
+	publish := func(b *broadcaster, n int) {
+		for i := 0; i < n; i++ {
+			b.publish(&domain.Order{OrderUID: fmt.Sprintf("order-%d", i)})
+		}
+	}
+
+	tests := []struct {
+		name        string
+		published   int
+		lastEventID func(b *broadcaster) string
+		wantBacklog int
+		wantReset   bool
+	}{
+		{"new subscriber", 5, func(*broadcaster) string { return "" }, 0, false},
+		{"resume in buffer", 5, func(b *broadcaster) string { return b.eventID(2) }, 3, false},
+		{"resume before first event", 5, func(b *broadcaster) string { return b.eventID(0) }, 5, false},
+		{"resume at latest", 5, func(b *broadcaster) string { return b.eventID(5) }, 0, false},
+		{"resume at oldest boundary", replaySize + 10, func(b *broadcaster) string { return b.eventID(10) }, replaySize, false},
+		{"older than buffer", replaySize + 10, func(b *broadcaster) string { return b.eventID(9) }, 0, true},
+		{"other process", 5, func(*broadcaster) string { return "otherepoch-3" }, 0, true},
+		{"from the future", 5, func(b *broadcaster) string { return b.eventID(6) }, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBroadcaster()
+			publish(b, tt.published)
+
+			sub := b.subscribe(tt.lastEventID(b), 1)
+			defer sub.Close()
+
+			if got := len(sub.events); got != tt.wantBacklog {
+				t.Errorf("backlog = %d, want %d", got, tt.wantBacklog)
+			}
+			id, reset := sub.Reset()
+			if reset != tt.wantReset {
+				t.Fatalf("reset = %v, want %v", reset, tt.wantReset)
+			}
+			// После сброса клиент продолжает с последнего события и больше не получает reset
+			if reset && id != b.eventID(uint64(tt.published)) {
+				t.Errorf("reset id = %q, want %q", id, b.eventID(uint64(tt.published)))
+			}
+			if reset {
+				if _, again := b.subscribe(id, 1).Reset(); again {
+					t.Errorf("resubscribe with reset id %q is reset again", id)
+				}
+			}
+		})
+	}
+}
+
+func TestBroadcasterStop(t *testing.T) {
+	b := newBroadcaster()
+	before := b.subscribe("", 1)
+	b.stop()
+	after := b.subscribe("", 1)
+
+	for name, sub := range map[string]*Subscription{"before stop": before, "after stop": after} {
+		if _, ok := <-sub.Events(); ok {
+			t.Fatalf("%s: events channel is open", name)
+		}
+		if !errors.Is(sub.Err(), ErrServiceStopped) {
+			t.Errorf("%s: Err() = %v, want ErrServiceStopped", name, sub.Err())
+		}
+		sub.Close()
+	}
+}
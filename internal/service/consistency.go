@@ -0,0 +1,103 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/platonso/order-viewer/internal/domain"
+)
+
+// Severity определяет, как реагировать на нарушение правила согласованности.
+type Severity string
+
+const (
+	SeverityError Severity = "error" // заказ отклоняется
+	SeverityWarn  Severity = "warn"  // заказ принимается и сохраняется с предупреждением
+	SeverityOff   Severity = "off"   // правило не проверяется
+)
+
+// Правила согласованности платежа и товаров.
+const (
+	RulePaymentAmount      = "payment_amount"
+	RuleGoodsTotal         = "goods_total"
+	RuleItemTotalPrice     = "item_total_price"
+	RuleItemTrackNumber    = "item_track_number"
+	RulePaymentTransaction = "payment_transaction"
+)
+
+// ConsistencyRules сопоставляет правилу его строгость; отсутствующие правила выключены.
+type ConsistencyRules map[string]Severity
+
+// NewConsistencyRules разбирает строгость правил из строковых значений конфигурации.
+func NewConsistencyRules(severities map[string]string) (ConsistencyRules, error) {
+	rules := make(ConsistencyRules, len(severities))
+	for rule, value := range severities {
+		severity := Severity(value)
+		switch severity {
+		case SeverityError, SeverityWarn, SeverityOff:
+			rules[rule] = severity
+		default:
+			return nil, fmt.Errorf("invalid severity %q for rule %s (expected error, warn or off)", value, rule)
+		}
+	}
+	return rules, nil
+}
+
+// enabled сообщает, проверяется ли правило: отсутствующее в конфигурации правило выключено.
+func (r ConsistencyRules) enabled(rule string) bool {
+	return r[rule] == SeverityError || r[rule] == SeverityWarn
+}
+
+// check проверяет согласованность заказа и раскладывает нарушения на ошибки и предупреждения.
+func (r ConsistencyRules) check(order *domain.Order, errs *domain.ValidationErrors) []*domain.FieldError {
+	var warnings domain.ValidationErrors
+
+	report := func(rule, field, message string) {
+		switch r[rule] {
+		case SeverityError:
+			errs.Add(field, rule, message)
+		case SeverityWarn:
+			warnings.Add(field, rule, message)
+		}
+	}
+
+	if r.enabled(RulePaymentAmount) {
+		p := order.Payment
+		expected := p.GoodsTotal + p.DeliveryCost + p.CustomFee
+		if p.Amount != expected {
+			report(RulePaymentAmount, "payment.amount",
+				fmt.Sprintf("must equal goods_total + delivery_cost + custom_fee (%d)", expected))
+		}
+	}
+
+	if r.enabled(RuleGoodsTotal) {
+		sum := 0
+		for _, item := range order.Items {
+			sum += item.TotalPrice
+		}
+		if order.Payment.GoodsTotal != sum {
+			report(RuleGoodsTotal, "payment.goods_total",
+				fmt.Sprintf("must equal the sum of items total_price (%d)", sum))
+		}
+	}
+
+	if r.enabled(RulePaymentTransaction) && order.Payment.Transaction != order.OrderUID {
+		report(RulePaymentTransaction, "payment.transaction", "must equal order_uid")
+	}
+
+	for i, item := range order.Items {
+		if r.enabled(RuleItemTotalPrice) {
+			// Цена со скидкой округляется вниз; расхождение в 1 допускается из-за округления продюсером
+			expected := item.Price * (100 - item.Sale) / 100
+			if diff := item.TotalPrice - expected; diff < -1 || diff > 1 {
+				report(RuleItemTotalPrice, fmt.Sprintf("items[%d].total_price", i),
+					fmt.Sprintf("must equal price minus sale%% (%d)", expected))
+			}
+		}
+
+		if r.enabled(RuleItemTrackNumber) && item.TrackNumber != order.TrackNumber {
+			report(RuleItemTrackNumber, fmt.Sprintf("items[%d].track_number", i), "must equal order track_number")
+		}
+	}
+
+	return warnings
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/platonso/order-viewer/internal/domain"
+)
+
+func TestGoodsTotalRule(t *testing.T) {
+	mismatch := func(o *domain.Order) { o.Payment.GoodsTotal = 318 }
+	extraItem := func(o *domain.Order) {
+		o.Items = append(o.Items, domain.Item{ChrtID: 2, Price: 100, Name: "Brush", TotalPrice: 100})
+	}
+	matching := func(o *domain.Order) {
+		o.Items = append(o.Items, domain.Item{ChrtID: 2, Price: 100, Name: "Brush", TotalPrice: 100})
+		o.Payment.GoodsTotal += 100
+		o.Payment.Amount += 100
+	}
+
+	tests := []struct {
+		name      string
+		severity  Severity
+		mutate    func(o *domain.Order)
+		wantError bool
+		wantWarn  bool
+	}{
+		{"error rejects mismatch", SeverityError, mismatch, true, false},
+		{"error rejects unpriced extra item", SeverityError, extraItem, true, false},
+		{"error accepts matching sum", SeverityError, matching, false, false},
+		{"warn accepts mismatch with warning", SeverityWarn, mismatch, false, true},
+		{"off ignores mismatch", SeverityOff, mismatch, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService()
+			s.rules = ConsistencyRules{RuleGoodsTotal: tt.severity}
+
+			order := validOrder()
+			tt.mutate(order)
+			err := s.validateOrder(order)
+
+			var errs domain.ValidationErrors
+			errors.As(err, &errs)
+			if got := hasFieldError(errs, "payment.goods_total", RuleGoodsTotal); got != tt.wantError {
+				t.Errorf("error reported = %v, want %v (err: %v)", got, tt.wantError, err)
+			}
+			if got := hasFieldError(order.Warnings, "payment.goods_total", RuleGoodsTotal); got != tt.wantWarn {
+				t.Errorf("warning reported = %v, want %v (warnings: %v)", got, tt.wantWarn, domain.ValidationErrors(order.Warnings))
+			}
+		})
+	}
+}
+
+func TestConsistencyRules(t *testing.T) {
+	violations := []struct {
+		rule   string
+		field  string
+		mutate func(o *domain.Order)
+	}{
+		{RulePaymentAmount, "payment.amount", func(o *domain.Order) { o.Payment.Amount++ }},
+		{RuleGoodsTotal, "payment.goods_total", func(o *domain.Order) { o.Payment.GoodsTotal-- }},
+		{RuleItemTotalPrice, "items[0].total_price", func(o *domain.Order) { o.Items[0].TotalPrice += 2 }},
+		{RuleItemTrackNumber, "items[0].track_number", func(o *domain.Order) { o.Items[0].TrackNumber = "WBILMOTHERTRACK" }},
+		{RulePaymentTransaction, "payment.transaction", func(o *domain.Order) { o.Payment.Transaction = "other-transaction" }},
+	}
+	modes := []struct {
+		name      string
+		rules     func(rule string) ConsistencyRules
+		wantError bool
+		wantWarn  bool
+	}{
+		{"reject", func(rule string) ConsistencyRules { return ConsistencyRules{rule: SeverityError} }, true, false},
+		{"warn", func(rule string) ConsistencyRules { return ConsistencyRules{rule: SeverityWarn} }, false, true},
+		{"off", func(rule string) ConsistencyRules { return ConsistencyRules{rule: SeverityOff} }, false, false},
+		{"not configured", func(string) ConsistencyRules { return ConsistencyRules{} }, false, false},
+	}
+
+	for _, v := range violations {
+		for _, mode := range modes {
+			t.Run(v.rule+"/"+mode.name, func(t *testing.T) {
+				s := newTestService()
+				s.rules = mode.rules(v.rule)
+
+				order := validOrder()
+				v.mutate(order)
+				err := s.validateOrder(order)
+
+				var errs domain.ValidationErrors
+				if err != nil && !errors.As(err, &errs) {
+					t.Fatalf("expected ValidationErrors, got %T: %v", err, err)
+				}
+				if (err != nil) != mode.wantError || hasFieldError(errs, v.field, v.rule) != mode.wantError {
+					t.Errorf("error = %v, want %s/%s reported: %v", err, v.field, v.rule, mode.wantError)
+				}
+				if hasFieldError(order.Warnings, v.field, v.rule) != mode.wantWarn || (!mode.wantWarn && len(order.Warnings) != 0) {
+					t.Errorf("warnings = %v, want %s/%s reported: %v", domain.ValidationErrors(order.Warnings), v.field, v.rule, mode.wantWarn)
+				}
+			})
+		}
+	}
+
+	t.Run("all rules pass a consistent order", func(t *testing.T) {
+		s := newTestService()
+		s.rules = ConsistencyRules{}
+		for _, v := range violations {
+			s.rules[v.rule] = SeverityError
+		}
+		order := validOrder()
+		if err := s.validateOrder(order); err != nil || len(order.Warnings) != 0 {
+			t.Fatalf("consistent order: err = %v, warnings = %v", err, domain.ValidationErrors(order.Warnings))
+		}
+	})
+}
+
+func TestItemTotalPriceTolerance(t *testing.T) {
+	// 453 со скидкой 30% — 317,1, округляется вниз до 317
+	tests := []struct {
+		totalPrice int
+		wantError  bool
+	}{
+		{315, true},
+		{316, false},
+		{317, false},
+		{318, false},
+		{319, true},
+	}
+
+	for _, tt := range tests {
+		s := newTestService()
+		s.rules = ConsistencyRules{RuleItemTotalPrice: SeverityError}
+
+		order := validOrder()
+		order.Items[0].TotalPrice = tt.totalPrice
+		err := s.validateOrder(order)
+
+		var errs domain.ValidationErrors
+		errors.As(err, &errs)
+		if got := hasFieldError(errs, "items[0].total_price", RuleItemTotalPrice); got != tt.wantError {
+			t.Errorf("total_price %d: rejected = %v, want %v (err: %v)", tt.totalPrice, got, tt.wantError, err)
+		}
+	}
+}
+
+func hasFieldError(errs []*domain.FieldError, field, rule string) bool {
+	for _, fe := range errs {
+		if fe.Field == field && fe.Rule == rule {
+			return true
+		}
+	}
+	return false
+}
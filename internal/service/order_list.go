@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/platonso/order-viewer/internal/domain"
+	"github.com/platonso/order-viewer/internal/tracing"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// ListOrders ищет заказы по фильтру и возвращает страницу кратких представлений.
+// cursor — непрозрачная строка из next_cursor предыдущей страницы.
+func (s *OrderService) ListOrders(ctx context.Context, filter domain.OrderFilter, cursor string) (*domain.OrderPage, error) {
+	ctx, span := tracer.Start(ctx, "OrderService.ListOrders")
+	defer span.End()
+
+	var errs domain.ValidationErrors
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultListLimit
+	} else if filter.Limit < 0 || filter.Limit > maxListLimit {
+		errs.Add("limit", "range", "must be between 1 and 100")
+	}
+
+	switch filter.Sort {
+	case "":
+		filter.Sort = domain.SortDateDesc
+	case domain.SortDateDesc, domain.SortDateAsc, domain.SortOrderUIDAsc, domain.SortOrderUIDDesc:
+	default:
+		errs.Add("sort", "oneof", "must be one of date_created, -date_created, order_uid, -order_uid")
+	}
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		errs.Add("to", "gtfield", "must be after from")
+	}
+
+	if cursor != "" {
+		after, err := decodeCursor(cursor)
+		if err != nil || after.Sort != filter.Sort {
+			errs.Add("cursor", "cursor", "is invalid or does not match the sort order")
+		} else {
+			filter.After = after
+		}
+	}
+
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	// Запрашиваем на один заказ больше, чтобы понять, есть ли следующая страница
+	limit := filter.Limit
+	filter.Limit++
+
+	summaries, err := s.dbRepo.List(ctx, filter)
+	if err != nil {
+		tracing.Fail(span, err)
+		return nil, err
+	}
+
+	page := &domain.OrderPage{Orders: summaries}
+	if len(summaries) > limit {
+		page.Orders = summaries[:limit]
+		last := page.Orders[limit-1]
+		page.NextCursor = encodeCursor(domain.OrderCursor{
+			Sort:        filter.Sort,
+			DateCreated: last.DateCreated,
+			OrderUID:    last.OrderUID,
+		})
+	}
+
+	return page, nil
+}
+
+func encodeCursor(c domain.OrderCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (*domain.OrderCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var c domain.OrderCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+
+	"github.com/platonso/order-viewer/internal/domain"
+	"github.com/platonso/order-viewer/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const maxLookupSize = 100
+
+// LookupOrders ищет несколько заказов: сначала в кэше, затем недостающие — одним запросом к бд.
+// Результаты возвращаются в порядке запроса, повторяющиеся uid обрабатываются один раз.
+func (s *OrderService) LookupOrders(ctx context.Context, orderUIDs []string) ([]domain.OrderLookup, error) {
+	ctx, span := tracer.Start(ctx, "OrderService.LookupOrders", trace.WithAttributes(attribute.Int("order.count", len(orderUIDs))))
+	defer span.End()
+
+	if len(orderUIDs) == 0 {
+		return nil, domain.NewFieldError("order_uids", "min", "must contain at least 1 element(s)")
+	}
+	if len(orderUIDs) > maxLookupSize {
+		return nil, domain.NewFieldError("order_uids", "max", "must contain at most 100 element(s)")
+	}
+
+	results := make([]domain.OrderLookup, 0, len(orderUIDs))
+	index := make(map[string]int, len(orderUIDs))
+	var missing []string
+
+	for _, uid := range orderUIDs {
+		if _, seen := index[uid]; seen {
+			continue
+		}
+		index[uid] = len(results)
+
+		result := domain.OrderLookup{OrderUID: uid}
+		if err := validateOrderUID(uid); err != nil {
+			result.Status = domain.LookupInvalid
+			result.Error = err.Error()
+		} else if order, ok := s.cacheRepo.FindByID(uid); ok {
+			s.observeCache(true)
+			result.Status = domain.LookupFound
+			result.Source = domain.SourceCache
+			result.Order = order
+		} else {
+			s.observeCache(false)
+			result.Status = domain.LookupNotFound
+			missing = append(missing, uid)
+		}
+		results = append(results, result)
+	}
+
+	span.SetAttributes(attribute.Int("cache.misses", len(missing)))
+	if len(missing) == 0 {
+		return results, nil
+	}
+
+	orders, err := s.dbRepo.FindByIDs(ctx, missing)
+	if err != nil {
+		tracing.Fail(span, err)
+		return nil, err
+	}
+
+	for uid, order := range orders {
+		s.cacheRepo.Save(order)
+
+		result := &results[index[uid]]
+		result.Status = domain.LookupFound
+		result.Source = domain.SourceDatabase
+		result.Order = order
+	}
+
+	return results, nil
+}
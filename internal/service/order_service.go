@@ -4,211 +4,206 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/go-playground/validator/v10"
 	"github.com/platonso/order-viewer/internal/domain"
+	"github.com/platonso/order-viewer/internal/logging"
+	"github.com/platonso/order-viewer/internal/metrics"
 	"github.com/platonso/order-viewer/internal/repository"
-	"log"
+	"github.com/platonso/order-viewer/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
 	"regexp"
-	"strings"
-	"time"
 )
 
+// Метрики сервиса: обращения к кэшу по результату и исходы сохранения заказа.
+const (
+	metricCacheRequests = "order_cache_requests_total"
+	metricOrderSaves    = "order_saves_total"
+)
+
+var tracer = tracing.Tracer("internal/service")
+
 type OrderService struct {
 	dbRepo    repository.DBRepository
 	cacheRepo repository.CacheRepository
+	validate  *validator.Validate
+	rules     ConsistencyRules
+	events    *broadcaster
+	metrics   metrics.Recorder
 }
 
-func NewOrderService(dbRepo repository.DBRepository, cacheRepo repository.CacheRepository) *OrderService {
+func NewOrderService(dbRepo repository.DBRepository, cacheRepo repository.CacheRepository, rules ConsistencyRules, recorder metrics.Recorder) *OrderService {
 	return &OrderService{
 		dbRepo:    dbRepo,
 		cacheRepo: cacheRepo,
+		validate:  newValidator(),
+		rules:     rules,
+		events:    newBroadcaster(),
+		metrics:   recorder,
 	}
 }
 
 func (s *OrderService) SaveOrder(ctx context.Context, order *domain.Order) error {
+	ctx, span := tracer.Start(ctx, "OrderService.SaveOrder")
+	defer span.End()
+	if order != nil {
+		span.SetAttributes(attribute.String("order.uid", order.OrderUID))
+	}
+
+	err := s.saveOrder(ctx, order)
+
+	outcome := saveOutcome(err)
+	s.metrics.Inc(metricOrderSaves, "outcome", outcome)
+	span.SetAttributes(attribute.String("order.save_outcome", outcome))
+	// Отказы из-за данных клиента — штатный исход, ошибкой спан помечают только сбои
+	if outcome == "error" {
+		tracing.Fail(span, err)
+	}
+	return err
+}
+
+// saveOutcome — класс ошибки сохранения для метрик.
+func saveOutcome(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, domain.ErrValidation):
+		return "validation"
+	case errors.Is(err, domain.ErrOrderAlreadyExists):
+		return "duplicate"
+	default:
+		return "error"
+	}
+}
+
+func (s *OrderService) saveOrder(ctx context.Context, order *domain.Order) error {
 
 	// Валидация всех полей заказа
 	if err := s.validateOrder(order); err != nil {
-		return fmt.Errorf("%w: %v", domain.ErrValidation, err)
+		return fmt.Errorf("%w: %w", domain.ErrValidation, err)
+	}
+
+	if len(order.Warnings) > 0 {
+		slog.WarnContext(ctx, "order accepted with warnings",
+			"order_uid", order.OrderUID, "warnings", domain.ValidationErrors(order.Warnings).Error())
 	}
 
 	// Сохранение нового заказа в бд
 	if err := s.dbRepo.Save(ctx, order); err != nil {
-		log.Printf("failed to save order in db: %v", err)
+		if !errors.Is(err, domain.ErrOrderAlreadyExists) {
+			slog.ErrorContext(ctx, "failed to save order in db", "order_uid", order.OrderUID, logging.Err(err))
+		}
 		return err
 	}
+	slog.InfoContext(ctx, "order saved", "order_uid", order.OrderUID)
 	// Добавление в кэш
 	s.cacheRepo.Save(order)
 
+	// Уведомление подписчиков о новом заказе
+	s.events.publish(order)
+
 	return nil
 }
 
-func (s *OrderService) GetOrder(ctx context.Context, orderUID string) (*domain.Order, bool, error) {
+// Subscribe подписывает на заказы, сохранённые после вызова, или после события lastEventID,
+// если оно ещё в буфере; иначе подписка сообщает о сбросе через Reset.
+// Заказы в событиях общие с кешем и не должны изменяться.
+func (s *OrderService) Subscribe(lastEventID string, buffer int) *Subscription {
+	return s.events.subscribe(lastEventID, buffer)
+}
 
-	// Валидация uid заказа
-	if orderUID == "" {
-		return nil, false, fmt.Errorf("%w: order id is required", domain.ErrValidation)
-	}
+// Stop закрывает подписки на ленту заказов, чтобы остановка серверов не ждала долгих потоков.
+func (s *OrderService) Stop() {
+	s.events.stop()
+}
 
-	if len(orderUID) > 36 {
-		return nil, false, fmt.Errorf("%w: order id is too long", domain.ErrValidation)
-	}
+func (s *OrderService) GetOrder(ctx context.Context, orderUID string) (*domain.Order, bool, error) {
+	ctx, span := tracer.Start(ctx, "OrderService.GetOrder", trace.WithAttributes(attribute.String("order.uid", orderUID)))
+	defer span.End()
 
-	validID := regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
-	if !validID.MatchString(orderUID) {
-		return nil, false, fmt.Errorf("%w: order id contains invalid characters", domain.ErrValidation)
+	// Валидация uid заказа
+	if err := validateOrderUID(orderUID); err != nil {
+		return nil, false, err
 	}
 
 	// Попытка достать заказ из кэша
+	_, cacheSpan := tracer.Start(ctx, "cache.find")
 	order, ok := s.cacheRepo.FindByID(orderUID)
+	cacheSpan.SetAttributes(attribute.Bool("cache.hit", ok))
+	cacheSpan.End()
+	s.observeCache(ok)
 	if ok {
+		span.SetAttributes(attribute.String("order.source", domain.SourceCache))
+		slog.DebugContext(ctx, "order read", "order_uid", orderUID, "source", domain.SourceCache)
 		return order, true, nil
 	}
 
 	// При отсутствии заказа в кэше, поиск его в бд
 	order, err := s.dbRepo.FindByID(ctx, orderUID)
 	if err != nil {
+		if !errors.Is(err, domain.ErrOrderNotFound) {
+			tracing.Fail(span, err)
+		}
 		return nil, false, err
 	}
 
 	// Добавление заказа в кэш, если он нашёлся в бд
 	if order != nil {
 		s.cacheRepo.Save(order)
+		span.SetAttributes(attribute.String("order.source", domain.SourceDatabase))
+		slog.DebugContext(ctx, "order read", "order_uid", orderUID, "source", domain.SourceDatabase)
 		return order, false, nil
 	}
 
 	return nil, false, domain.ErrOrderNotFound
 }
 
-func (s *OrderService) validateOrder(order *domain.Order) error {
-	if order == nil {
-		return errors.New("order is nil")
-	}
-
-	if order.OrderUID == "" {
-		return errors.New("order_uid is required")
-	}
-	if len(order.OrderUID) > 36 {
-		return errors.New("order_uid is too long (max 36 characters)")
-	}
-	if strings.Contains(order.OrderUID, " ") {
-		return errors.New("order_uid cannot contain spaces")
-	}
-
-	if order.TrackNumber == "" {
-		return errors.New("track_number is required")
-	}
-	if len(order.TrackNumber) > 36 {
-		return errors.New("track_number is too long (max 36 characters)")
-	}
-
-	if order.DateCreated.IsZero() {
-		return errors.New("date_created is required")
-	}
-	if order.DateCreated.After(time.Now().Add(time.Hour)) {
-		return errors.New("date_created cannot be in the future")
+// observeCache учитывает попадание или промах кэша.
+func (s *OrderService) observeCache(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
 	}
-
-	if err := s.validateDelivery(&order.Delivery); err != nil {
-		return fmt.Errorf("delivery validation failed: %w", err)
-	}
-
-	if err := s.validatePayment(&order.Payment); err != nil {
-		return fmt.Errorf("payment validation failed: %w", err)
-	}
-
-	if len(order.Items) == 0 {
-		return errors.New("at least one item is required")
-	}
-	for i, item := range order.Items {
-		if err := s.validateItem(&item); err != nil {
-			return fmt.Errorf("item[%d] validation failed: %w", i, err)
-		}
-	}
-
-	return nil
-}
-
-func (s *OrderService) validateDelivery(delivery *domain.Delivery) error {
-	if delivery == nil {
-		return errors.New("delivery is nil")
-	}
-
-	if delivery.Name == "" {
-		return errors.New("delivery name is required")
-	}
-	if len(delivery.Name) > 100 {
-		return errors.New("delivery name is too long (max 100 characters)")
-	}
-
-	if delivery.Phone == "" {
-		return errors.New("delivery phone is required")
-	}
-
-	if delivery.Email == "" {
-		return errors.New("delivery email is required")
-	}
-	if len(delivery.Email) > 254 {
-		return errors.New("delivery email is too long (max 254 characters)")
-	}
-	if !strings.Contains(delivery.Email, "@") || !strings.Contains(delivery.Email, ".") {
-		return errors.New("delivery email format is invalid")
-	}
-
-	return nil
+	s.metrics.Inc(metricCacheRequests, "result", result)
 }
 
-func (s *OrderService) validatePayment(payment *domain.Payment) error {
-	if payment == nil {
-		return errors.New("payment is nil")
-	}
+var validOrderUID = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
 
-	if payment.Transaction == "" {
-		return errors.New("payment transaction is required")
-	}
-	if len(payment.Transaction) > 36 {
-		return errors.New("payment transaction is too long (max 36 characters)")
+func validateOrderUID(orderUID string) error {
+	if orderUID == "" {
+		return domain.NewFieldError("order_uid", "required", "is required")
 	}
 
-	if payment.Amount <= 0 {
-		return errors.New("payment amount must be greater than 0")
+	if len(orderUID) > 36 {
+		return domain.NewFieldError("order_uid", "max_len", "is too long")
 	}
 
-	if payment.Currency == "" {
-		return errors.New("payment currency is required")
-	}
-	if len(payment.Currency) > 3 {
-		return errors.New("payment currency code is invalid (max 3 characters)")
+	if !validOrderUID.MatchString(orderUID) {
+		return domain.NewFieldError("order_uid", "pattern", "contains invalid characters")
 	}
 
 	return nil
 }
 
-func (s *OrderService) validateItem(item *domain.Item) error {
-	if item == nil {
-		return errors.New("item is nil")
-	}
-
-	if item.Name == "" {
-		return errors.New("item name is required")
-	}
-	if len(item.Name) > 200 {
-		return errors.New("item name is too long (max 200 characters)")
-	}
-
-	if item.Price <= 0 {
-		return errors.New("item price must be greater than 0")
-	}
-	if item.Price > 100000000 {
-		return errors.New("item price is too large")
+// validateOrder проверяет заказ по тегам и правилам согласованности.
+// Предупреждения от правил со строгостью warn записываются в order.Warnings.
+func (s *OrderService) validateOrder(order *domain.Order) error {
+	if order == nil {
+		return domain.ValidationErrors{domain.NewFieldError("", "required", "order is nil")}
 	}
 
-	if item.TotalPrice < 0 {
-		return errors.New("item total_price cannot be negative")
+	var errs domain.ValidationErrors
+	if err := toValidationErrors(s.validate.Struct(order)); err != nil {
+		var tagErrs domain.ValidationErrors
+		if !errors.As(err, &tagErrs) {
+			return err
+		}
+		errs = tagErrs
 	}
 
-	if item.ChrtID <= 0 {
-		return errors.New("item chrt_id must be greater than 0")
-	}
+	// Предупреждения вычисляются только сервисом, значение из входящих данных не принимается
+	order.Warnings = s.rules.check(order, &errs)
 
-	return nil
+	return errs.Err()
 }
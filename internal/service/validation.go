@@ -0,0 +1,126 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/platonso/order-viewer/internal/domain"
+	"golang.org/x/text/language"
+)
+
+// maxFutureSkew — допустимое расхождение часов продюсера при проверке date_created.
+const maxFutureSkew = time.Hour
+
+var phonePattern = regexp.MustCompile(`^\+?[0-9]{7,15}$`)
+
+// newValidator настраивает validator: имена полей берутся из json-тегов,
+// чтобы пути ошибок совпадали с путями в JSON (например, items[3].price).
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	_ = v.RegisterValidation("nospace", validateNoSpace)
+	_ = v.RegisterValidation("phone", validatePhone)
+	_ = v.RegisterValidation("locale", validateLocale)
+	_ = v.RegisterValidation("notfuture", validateNotFuture)
+	_ = v.RegisterValidation("currency", func(fl validator.FieldLevel) bool {
+		return v.Var(strings.ToUpper(fl.Field().String()), "iso4217") == nil
+	})
+
+	return v
+}
+
+func validateNoSpace(fl validator.FieldLevel) bool {
+	return !strings.ContainsAny(fl.Field().String(), " \t\r\n")
+}
+
+// validatePhone допускает номер в формате E.164 с пробелами, дефисами и скобками.
+func validatePhone(fl validator.FieldLevel) bool {
+	phone := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(fl.Field().String())
+	return phonePattern.MatchString(phone)
+}
+
+func validateLocale(fl validator.FieldLevel) bool {
+	_, err := language.Parse(fl.Field().String())
+	return err == nil
+}
+
+func validateNotFuture(fl validator.FieldLevel) bool {
+	t, ok := fl.Field().Interface().(time.Time)
+	if !ok {
+		return false
+	}
+	return !t.After(time.Now().Add(maxFutureSkew))
+}
+
+// toValidationErrors переводит ошибки validator в domain.ValidationErrors.
+func toValidationErrors(err error) error {
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return err
+	}
+
+	var errs domain.ValidationErrors
+	for _, fe := range fieldErrs {
+		errs.Add(fieldPath(fe), fe.Tag(), fieldMessage(fe))
+	}
+	return errs.Err()
+}
+
+// fieldPath отбрасывает имя корневой структуры: "Order.items[3].price" -> "items[3].price".
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.Index(ns, "."); i >= 0 {
+		return ns[i+1:]
+	}
+	return ns
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "max":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("is too long (max %s characters)", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "min":
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must contain at least %s element(s)", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "gte":
+		return fmt.Sprintf("must be greater than or equal to %s", fe.Param())
+	case "lte":
+		return fmt.Sprintf("must be less than or equal to %s", fe.Param())
+	case "nospace":
+		return "cannot contain spaces"
+	case "email":
+		return "format is invalid"
+	case "phone":
+		return "is not a valid phone number"
+	case "currency":
+		return "is not a valid ISO-4217 currency code"
+	case "locale":
+		return "is not a valid locale"
+	case "notfuture":
+		return "cannot be in the future"
+	default:
+		return fmt.Sprintf("failed on the %q rule", fe.Tag())
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/platonso/order-viewer/internal/domain"
+)
+
+func validOrder() *domain.Order {
+	return &domain.Order{
+		OrderUID:    "b563feb7b2b84b6test",
+		TrackNumber: "WBILMTESTTRACK",
+		Entry:       "WBIL",
+		Locale:      "en",
+		DateCreated: time.Now().Add(-time.Hour),
+		Delivery: domain.Delivery{
+			Name:  "Test Testov",
+			Phone: "+9720000000",
+			City:  "Kiryat Mozkin",
+			Email: "test@gmail.com",
+		},
+		Payment: domain.Payment{
+			Transaction:  "b563feb7b2b84b6test",
+			Currency:     "USD",
+			Amount:       1817,
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+		},
+		Items: []domain.Item{
+			{ChrtID: 9934930, TrackNumber: "WBILMTESTTRACK", Price: 453, Name: "Mascaras", Sale: 30, TotalPrice: 317},
+		},
+	}
+}
+
+func newTestService() *OrderService {
+	return &OrderService{validate: newValidator(), rules: ConsistencyRules{}}
+}
+
+func TestValidateOrderValid(t *testing.T) {
+	if err := newTestService().validateOrder(validOrder()); err != nil {
+		t.Fatalf("valid order rejected: %v", err)
+	}
+}
+
+func TestValidateOrderRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(o *domain.Order)
+		field  string
+		rule   string
+	}{
+		{"order_uid required", func(o *domain.Order) { o.OrderUID = "" }, "order_uid", "required"},
+		{"order_uid max", func(o *domain.Order) { o.OrderUID = strings.Repeat("a", 37) }, "order_uid", "max"},
+		{"order_uid nospace", func(o *domain.Order) { o.OrderUID = "abc def" }, "order_uid", "nospace"},
+		{"track_number required", func(o *domain.Order) { o.TrackNumber = "" }, "track_number", "required"},
+		{"track_number max", func(o *domain.Order) { o.TrackNumber = strings.Repeat("T", 37) }, "track_number", "max"},
+		{"items min", func(o *domain.Order) { o.Items = nil; o.Payment.GoodsTotal = 0 }, "items", "min"},
+		{"locale invalid", func(o *domain.Order) { o.Locale = "not a locale" }, "locale", "locale"},
+		{"date_created required", func(o *domain.Order) { o.DateCreated = time.Time{} }, "date_created", "required"},
+		{"date_created notfuture", func(o *domain.Order) { o.DateCreated = time.Now().Add(2 * time.Hour) }, "date_created", "notfuture"},
+
+		{"delivery.name required", func(o *domain.Order) { o.Delivery.Name = "" }, "delivery.name", "required"},
+		{"delivery.name max", func(o *domain.Order) { o.Delivery.Name = strings.Repeat("n", 101) }, "delivery.name", "max"},
+		{"delivery.phone required", func(o *domain.Order) { o.Delivery.Phone = "" }, "delivery.phone", "required"},
+		{"delivery.phone too short", func(o *domain.Order) { o.Delivery.Phone = "+12-34" }, "delivery.phone", "phone"},
+		{"delivery.phone letters", func(o *domain.Order) { o.Delivery.Phone = "+7999abc4567" }, "delivery.phone", "phone"},
+		{"delivery.phone too long", func(o *domain.Order) { o.Delivery.Phone = "+1234567890123456" }, "delivery.phone", "phone"},
+		{"delivery.email required", func(o *domain.Order) { o.Delivery.Email = "" }, "delivery.email", "required"},
+		{"delivery.email format", func(o *domain.Order) { o.Delivery.Email = "test.gmail.com" }, "delivery.email", "email"},
+		{"delivery.email max", func(o *domain.Order) { o.Delivery.Email = strings.Repeat("a", 250) + "@x.io" }, "delivery.email", "max"},
+
+		{"payment.transaction required", func(o *domain.Order) { o.Payment.Transaction = "" }, "payment.transaction", "required"},
+		{"payment.transaction max", func(o *domain.Order) { o.Payment.Transaction = strings.Repeat("t", 37) }, "payment.transaction", "max"},
+		{"payment.currency required", func(o *domain.Order) { o.Payment.Currency = "" }, "payment.currency", "required"},
+		{"payment.currency unknown", func(o *domain.Order) { o.Payment.Currency = "XYZ" }, "payment.currency", "currency"},
+		{"payment.currency length", func(o *domain.Order) { o.Payment.Currency = "USDT" }, "payment.currency", "currency"},
+		{"payment.amount gt", func(o *domain.Order) { o.Payment.Amount = 0 }, "payment.amount", "gt"},
+		{"payment.delivery_cost gte", func(o *domain.Order) { o.Payment.DeliveryCost = -1 }, "payment.delivery_cost", "gte"},
+		{"payment.goods_total gte", func(o *domain.Order) { o.Payment.GoodsTotal = -1 }, "payment.goods_total", "gte"},
+		{"payment.custom_fee gte", func(o *domain.Order) { o.Payment.CustomFee = -1 }, "payment.custom_fee", "gte"},
+
+		{"items chrt_id gt", func(o *domain.Order) { o.Items[0].ChrtID = 0 }, "items[0].chrt_id", "gt"},
+		{"items price gt", func(o *domain.Order) { o.Items[0].Price = 0 }, "items[0].price", "gt"},
+		{"items price lte", func(o *domain.Order) { o.Items[0].Price = 100000001 }, "items[0].price", "lte"},
+		{"items name required", func(o *domain.Order) { o.Items[0].Name = "" }, "items[0].name", "required"},
+		{"items name max", func(o *domain.Order) { o.Items[0].Name = strings.Repeat("n", 201) }, "items[0].name", "max"},
+		{"items sale gte", func(o *domain.Order) { o.Items[0].Sale = -1 }, "items[0].sale", "gte"},
+		{"items sale lte", func(o *domain.Order) { o.Items[0].Sale = 101 }, "items[0].sale", "lte"},
+		{"items total_price gte", func(o *domain.Order) { o.Items[0].TotalPrice = -1 }, "items[0].total_price", "gte"},
+		{"items path uses index", func(o *domain.Order) {
+			o.Items = append(o.Items, domain.Item{ChrtID: 1, Price: 10, Name: "", TotalPrice: 10})
+			o.Payment.GoodsTotal += 10
+		}, "items[1].name", "required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := validOrder()
+			tt.mutate(order)
+
+			err := newTestService().validateOrder(order)
+			if err == nil {
+				t.Fatalf("expected %s/%s, got no error", tt.field, tt.rule)
+			}
+
+			var errs domain.ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("expected ValidationErrors, got %T: %v", err, err)
+			}
+			for _, fe := range errs {
+				if fe.Field == tt.field && fe.Rule == tt.rule {
+					return
+				}
+			}
+			t.Fatalf("expected %s/%s, got %v", tt.field, tt.rule, errs)
+		})
+	}
+}
+
+func TestValidateCustomFormats(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(o *domain.Order)
+	}{
+		{"phone E.164", func(o *domain.Order) { o.Delivery.Phone = "+79991234567" }},
+		{"phone without plus", func(o *domain.Order) { o.Delivery.Phone = "89991234567" }},
+		{"phone with separators", func(o *domain.Order) { o.Delivery.Phone = "+7 (999) 123-45-67" }},
+		{"currency lower case", func(o *domain.Order) { o.Payment.Currency = "rub" }},
+		{"currency EUR", func(o *domain.Order) { o.Payment.Currency = "EUR" }},
+		{"locale language", func(o *domain.Order) { o.Locale = "ru" }},
+		{"locale with region", func(o *domain.Order) { o.Locale = "en-US" }},
+		{"locale empty", func(o *domain.Order) { o.Locale = "" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := validOrder()
+			tt.mutate(order)
+			if err := newTestService().validateOrder(order); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateOrderUID(t *testing.T) {
+	tests := []struct {
+		uid  string
+		rule string
+	}{
+		{"b563feb7b2b84b6test", ""},
+		{"order_1-A", ""},
+		{"", "required"},
+		{strings.Repeat("a", 37), "max_len"},
+		{"abc/def", "pattern"},
+	}
+
+	for _, tt := range tests {
+		err := validateOrderUID(tt.uid)
+		if tt.rule == "" {
+			if err != nil {
+				t.Errorf("validateOrderUID(%q): unexpected error %v", tt.uid, err)
+			}
+			continue
+		}
+
+		var fe *domain.FieldError
+		if !errors.As(err, &fe) || fe.Field != "order_uid" || fe.Rule != tt.rule {
+			t.Errorf("validateOrderUID(%q) = %v, want order_uid/%s", tt.uid, err, tt.rule)
+		}
+	}
+}
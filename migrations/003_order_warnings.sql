@@ -0,0 +1 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS warnings JSONB;
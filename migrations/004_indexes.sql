@@ -0,0 +1,9 @@
+CREATE INDEX IF NOT EXISTS orders_customer_id_idx ON orders (customer_id);
+CREATE INDEX IF NOT EXISTS orders_track_number_idx ON orders (track_number);
+CREATE INDEX IF NOT EXISTS orders_delivery_service_idx ON orders (delivery_service);
+CREATE INDEX IF NOT EXISTS orders_date_created_idx ON orders (date_created, order_uid);
+
+CREATE INDEX IF NOT EXISTS deliveries_order_uid_idx ON deliveries (order_uid);
+CREATE INDEX IF NOT EXISTS payments_order_uid_idx ON payments (order_uid);
+CREATE INDEX IF NOT EXISTS items_order_uid_idx ON items (order_uid);
+CREATE INDEX IF NOT EXISTS items_brand_idx ON items (brand);
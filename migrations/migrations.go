@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"log"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"sort"
 )
 
 func Run(ctx context.Context, connStr string) error {
@@ -15,20 +17,27 @@ func Run(ctx context.Context, connStr string) error {
 	}
 	defer pool.Close()
 
-	path := "migrations/001_init.sql"
-
-	sqlBytes, err := os.ReadFile(path)
+	// Миграции применяются по порядку номеров в имени файла и должны быть идемпотентными
+	paths, err := filepath.Glob("migrations/*.sql")
 	if err != nil {
-		return fmt.Errorf("failed to read migration file: %w", err)
+		return fmt.Errorf("failed to list migration files: %w", err)
 	}
+	sort.Strings(paths)
 
-	sql := string(sqlBytes)
+	for _, path := range paths {
+		sqlBytes, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read migration file %s: %w", path, err)
+		}
 
-	_, err = pool.Exec(ctx, sql)
-	if err != nil {
-		return fmt.Errorf("failed to execute migration: %w", err)
+		sql := string(sqlBytes)
+
+		_, err = pool.Exec(ctx, sql)
+		if err != nil {
+			return fmt.Errorf("failed to execute migration %s: %w", path, err)
+		}
 	}
 
-	log.Println("Migrations applied successfully")
+	slog.Info("migrations applied", "count", len(paths))
 	return nil
 }
@@ -11,7 +11,11 @@ import (
 	"time"
 
 	"github.com/platonso/order-viewer/internal/domain"
+	orderkafka "github.com/platonso/order-viewer/internal/kafka"
+	"github.com/platonso/order-viewer/internal/logging"
+	"github.com/platonso/order-viewer/internal/pb/orderpb"
 	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
 )
 
 func main() {
@@ -21,8 +25,15 @@ func main() {
 	topic := flag.String("topic", "orders", "Kafka topic")
 	numOrders := flag.Int("orders", 10, "Number of orders to send")
 	interval := flag.Duration("interval", 300*time.Millisecond, "Interval between messages")
+	format := flag.String("format", "json", "Payload format: json, protobuf or avro")
+	avroSchemaDir := flag.String("avro-schema-dir", "api/avro", "Directory with Avro schemas (order-v<version>.avsc)")
 	flag.Parse()
 
+	encode, contentType, err := newEncoder(*format, *avroSchemaDir)
+	if err != nil {
+		log.Fatalf("failed to init encoder: %v", err)
+	}
+
 	brokers := splitCSV(*brokersCSV)
 	writer := kafka.NewWriter(kafka.WriterConfig{
 		Brokers:  brokers,
@@ -31,11 +42,11 @@ func main() {
 	})
 	defer writer.Close()
 
-	log.Printf("Sending %d orders to %v topic %q...", *numOrders, brokers, *topic)
+	log.Printf("Sending %d %s orders to %v topic %q...", *numOrders, *format, brokers, *topic)
 
 	for i := 0; i < *numOrders; i++ {
 		order := generateOrder()
-		data, err := json.Marshal(order)
+		data, err := encode(&order)
 		if err != nil {
 			log.Printf("failed to marshal order: %v", err)
 			continue
@@ -44,6 +55,11 @@ func main() {
 		err = writer.WriteMessages(context.Background(), kafka.Message{
 			Key:   []byte(order.OrderUID),
 			Value: data,
+			Headers: []kafka.Header{
+				{Key: orderkafka.ContentTypeHeader, Value: []byte(contentType)},
+				{Key: orderkafka.SchemaVersionHeader, Value: []byte("1")},
+				{Key: orderkafka.CorrelationIDHeader, Value: []byte(logging.NewID())},
+			},
 		})
 		if err != nil {
 			log.Printf("failed to send order: %v", err)
@@ -53,6 +69,30 @@ func main() {
 	}
 }
 
+// newEncoder возвращает функцию сериализации заказа и соответствующий content-type.
+func newEncoder(format, avroSchemaDir string) (func(*domain.Order) ([]byte, error), string, error) {
+	switch format {
+	case "json":
+		return func(o *domain.Order) ([]byte, error) {
+			return json.Marshal(o)
+		}, orderkafka.ContentTypeJSON, nil
+	case "protobuf":
+		return func(o *domain.Order) ([]byte, error) {
+			return proto.Marshal(orderpb.FromDomain(o))
+		}, orderkafka.ContentTypeProtobuf, nil
+	case "avro":
+		registry, err := orderkafka.NewAvroRegistry(avroSchemaDir)
+		if err != nil {
+			return nil, "", err
+		}
+		return func(o *domain.Order) ([]byte, error) {
+			return registry.Marshal("1", o)
+		}, orderkafka.ContentTypeAvro, nil
+	default:
+		return nil, "", fmt.Errorf("unknown format %q", format)
+	}
+}
+
 func splitCSV(s string) []string {
 	parts := strings.Split(s, ",")
 	out := make([]string, 0, len(parts))
@@ -70,27 +110,34 @@ func splitCSV(s string) []string {
 
 func generateOrder() domain.Order {
 	uid := fmt.Sprintf("test-%d", rand.Intn(1_000_000))
+	trackNumber := fmt.Sprintf("TRACK-%04d", rand.Intn(10000))
 
+	goodsTotal := 0
 	items := make([]domain.Item, rand.Intn(5)+1) // 1..5
 	for i := range items {
+		price := rand.Intn(1000) + 1
+		sale := rand.Intn(50)
 		items[i] = domain.Item{
-			ChrtID:      rand.Intn(100000),
-			TrackNumber: fmt.Sprintf("TRACK-%04d", rand.Intn(10000)),
-			Price:       rand.Intn(1000) + 1,
+			ChrtID:      rand.Intn(100000) + 1,
+			TrackNumber: trackNumber,
+			Price:       price,
 			RID:         fmt.Sprintf("rid-%d", rand.Intn(100000)),
 			Name:        fmt.Sprintf("Product-%d", i+1),
-			Sale:        rand.Intn(50),
+			Sale:        sale,
 			Size:        "M",
-			TotalPrice:  rand.Intn(2000) + 1,
+			TotalPrice:  price * (100 - sale) / 100,
 			NmID:        rand.Intn(10000),
 			Brand:       "BrandX",
 			Status:      200,
 		}
+		goodsTotal += items[i].TotalPrice
 	}
 
+	deliveryCost := 1500
+
 	return domain.Order{
 		OrderUID:    uid,
-		TrackNumber: fmt.Sprintf("TRACK-%04d", rand.Intn(10000)),
+		TrackNumber: trackNumber,
 		Entry:       "WBIL",
 		Locale:      "en",
 		Delivery: domain.Delivery{
@@ -106,11 +153,11 @@ func generateOrder() domain.Order {
 			Transaction:  uid,
 			Currency:     "USD",
 			Provider:     "wbpay",
-			Amount:       rand.Intn(5000) + 1,
+			Amount:       goodsTotal + deliveryCost,
 			PaymentDt:    int(time.Now().Unix()),
 			Bank:         "alpha",
-			DeliveryCost: 1500,
-			GoodsTotal:   rand.Intn(3000) + 1,
+			DeliveryCost: deliveryCost,
+			GoodsTotal:   goodsTotal,
 			CustomFee:    0,
 		},
 		Items:           items,